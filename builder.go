@@ -0,0 +1,459 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/router source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package router
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"aahframework.org/ahttp.v0"
+	"aahframework.org/essentials.v0"
+)
+
+const defaultMaxBodySize = "5mb"
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Router methods
+//______________________________________________________________________________
+
+// AddDomain method adds the domain for given host and port into router and
+// returns it, if domain already exists then existing domain is returned.
+// Domain is created with the same defaults as routes config, domain values
+// can be changed before adding the routes. For e.g.:
+//
+//	d := rtr.AddDomain("sample.com", "8080")
+//	d.Group("/v1").Auth("jwt").GET("/users/:id", "User", "Show")
+//
+// If domain host conflicts with existing domain then domain is not added into
+// router, the error is kept and the domain route groups are no-op. Use method
+// `Router.Err` or `RouteGroup.Err` to get the error.
+func (r *Router) AddDomain(host, port string) *Domain {
+	port = strings.TrimSpace(port)
+	if port == "80" || port == "443" {
+		port = ""
+	}
+
+	domain := &Domain{
+		Name:                  host,
+		Host:                  host,
+		Port:                  port,
+		Scheme:                schemeHTTP,
+		IsSubDomain:           isHostPattern(host),
+		MethodNotAllowed:      true,
+		RedirectTrailingSlash: true,
+		AutoOptions:           true,
+		AntiCSRFEnabled:       true,
+		trees:                 make(map[string]*node),
+		routes:                make(map[string]*Route),
+		hostParams:            parseHostParams(host),
+		maxBodySizeStr:        r.requestMaxBodySize(),
+	}
+	domain.inferKey()
+
+	if d := r.findDomain(domain.Key); d != nil {
+		return d
+	}
+
+	if r.index == nil {
+		r.index = newHostIndex()
+	}
+	if err := r.index.add(domain); err != nil {
+		domain.err = fmt.Errorf("router: domain host %s", err)
+		if r.err == nil {
+			r.err = domain.err
+		}
+		return domain
+	}
+
+	r.Domains = append(r.Domains, domain)
+	if r.rootDomain == nil && !domain.IsSubDomain {
+		r.rootDomain = domain
+	}
+	return domain
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Domain methods
+//______________________________________________________________________________
+
+// Group method returns the route group for given path prefix on the domain.
+// Group starts with domain level values of auth, CORS, anti-CSRF check and
+// app config `request.max_body_size` (default `5mb`), same as routes config.
+func (d *Domain) Group(prefix string) *RouteGroup {
+	maxBodySizeStr := d.maxBodySizeStr
+	if len(maxBodySizeStr) == 0 {
+		maxBodySizeStr = defaultMaxBodySize
+	}
+	return &RouteGroup{
+		domain: d,
+		state:  &groupState{err: d.err},
+		info: &parentRouteInfo{
+			PrefixPath:        path.Join("/", prefix),
+			Auth:              d.DefaultAuth,
+			MaxBodySizeStr:    maxBodySizeStr,
+			CORS:              d.CORS,
+			AntiCSRFCheck:     d.AntiCSRFEnabled,
+			CORSEnabled:       d.CORSEnabled,
+			AuthorizationInfo: &authorizationInfo{Satisfy: "either"},
+			NamespaceNames:    d.NamespaceNames,
+		},
+	}
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// RouteGroup
+//______________________________________________________________________________
+
+// RouteGroup is used to add routes into domain programmatically. Group values
+// are inherited by the sub groups and routes same as nested routes in routes
+// config. Values are inherited at the time of sub group creation or route
+// addition, so set the group values first.
+//
+// Group methods are chainable, first error is kept and subsequent calls are
+// no-op, use method `Err` to get the error.
+type RouteGroup struct {
+	domain     *Domain
+	parentName string
+	info       *parentRouteInfo
+	defaults   map[string]string
+	state      *groupState
+}
+
+type groupState struct {
+	err error
+}
+
+// Group method returns the sub group for given path prefix, it inherits the
+// values of the group.
+func (g *RouteGroup) Group(prefix string) *RouteGroup {
+	info := *g.info
+	if len(prefix) > 0 && prefix[0] == '^' {
+		info.PrefixPath = path.Clean(addSlashPrefix(prefix[1:]))
+	} else {
+		info.PrefixPath = path.Join(g.info.PrefixPath, addSlashPrefix(prefix))
+	}
+	return &RouteGroup{domain: g.domain, parentName: g.info.ParentName, info: &info,
+		defaults: g.defaults, state: g.state}
+}
+
+// Defaults method returns the sub group with given default values of optional
+// path params, for e.g.:
+//
+//	g.Defaults(map[string]string{"period": "month"}).
+//		GET("/reports/:period?", "Report", "Show")
+//
+// Routes of the sub group must have the optional params.
+func (g *RouteGroup) Defaults(defaults map[string]string) *RouteGroup {
+	sg := g.Group("")
+	sg.defaults = defaults
+	return sg
+}
+
+// Name method sets the group name, it becomes the parent name of routes in
+// the group. On domain `namespace_names` mode, route names are prefixed with
+// the group name.
+func (g *RouteGroup) Name(name string) *RouteGroup {
+	if g.info.NamespaceNames && len(g.parentName) > 0 {
+		name = g.parentName + "." + name
+	}
+	g.info.ParentName = name
+	return g
+}
+
+// Controller method sets the default controller of the group, it is used when
+// the route controller is empty.
+func (g *RouteGroup) Controller(target string) *RouteGroup {
+	g.info.Target = target
+	return g
+}
+
+// Auth method sets the auth scheme name of the group.
+func (g *RouteGroup) Auth(name string) *RouteGroup {
+	g.info.Auth = strings.TrimSpace(name)
+	return g
+}
+
+// CORS method sets the CORS configuration of the group, `nil` disables the
+// CORS for the group. It is applicable only if domain CORS is enabled.
+func (g *RouteGroup) CORS(cors *CORS) *RouteGroup {
+	g.info.CORS = cors
+	return g
+}
+
+// MaxBodySize method sets the request max body size of the group, for e.g.:
+// `10mb`. It is applicable only to HTTP methods POST, PUT and DELETE.
+func (g *RouteGroup) MaxBodySize(size string) *RouteGroup {
+	if _, err := ess.StrToBytes(size); err != nil {
+		return g.setErr(fmt.Errorf("router: group '%s' max body size '%s' is not a valid size unit", g.info.PrefixPath, size))
+	}
+	g.info.MaxBodySizeStr = size
+	return g
+}
+
+// AntiCSRFCheck method enables or disables the Anti-CSRF check of the group.
+func (g *RouteGroup) AntiCSRFCheck(enable bool) *RouteGroup {
+	g.info.AntiCSRFCheck = enable
+	return g
+}
+
+// Roles method sets the authorization roles of the group, values are same as
+// routes config. For e.g.: `hasrole(manager)`, `hasanyrole(role1, role2)`.
+func (g *RouteGroup) Roles(roles ...string) *RouteGroup {
+	values, err := parseAuthorizationValues(roles, ",", fmt.Sprintf("group '%s' authorization roles", g.info.PrefixPath))
+	if err != nil {
+		return g.setErr(fmt.Errorf("router: %s", err))
+	}
+	info := *g.info.AuthorizationInfo
+	info.Roles = values
+	g.info.AuthorizationInfo = &info
+	return g
+}
+
+// Permissions method sets the authorization permissions of the group, values
+// are same as routes config. For e.g.: `ispermitted(newsletter:read,write)`.
+func (g *RouteGroup) Permissions(permissions ...string) *RouteGroup {
+	values, err := parseAuthorizationValues(permissions, "|", fmt.Sprintf("group '%s' authorization permissions", g.info.PrefixPath))
+	if err != nil {
+		return g.setErr(fmt.Errorf("router: %s", err))
+	}
+	info := *g.info.AuthorizationInfo
+	info.Permissions = values
+	g.info.AuthorizationInfo = &info
+	return g
+}
+
+// Satisfy method sets the authorization satisfy value of the group, either
+// `either` or `both`.
+func (g *RouteGroup) Satisfy(satisfy string) *RouteGroup {
+	info := *g.info.AuthorizationInfo
+	info.Satisfy = satisfy
+	g.info.AuthorizationInfo = &info
+	return g
+}
+
+// Meta method sets the meta value for given key on the group, it is merged
+// with the inherited meta values.
+func (g *RouteGroup) Meta(key string, value interface{}) *RouteGroup {
+	g.info.Meta = mergeRouteMeta(g.info.Meta, map[string]interface{}{key: value})
+	return g
+}
+
+// Interceptors method adds the given interceptor names to the group after
+// the inherited interceptors.
+func (g *RouteGroup) Interceptors(names ...string) *RouteGroup {
+	g.info.Interceptors = mergeValues(g.info.Interceptors, names)
+	return g
+}
+
+// Tags method adds the given tags to the group after the inherited tags.
+func (g *RouteGroup) Tags(tags ...string) *RouteGroup {
+	g.info.Tags = mergeValues(g.info.Tags, tags)
+	return g
+}
+
+// Owner method sets the owner of the group routes, for e.g.: team name.
+func (g *RouteGroup) Owner(owner string) *RouteGroup {
+	g.info.Owner = strings.TrimSpace(owner)
+	return g
+}
+
+// Deprecated method marks the group routes as deprecated with given sunset
+// time, zero time means no sunset.
+func (g *RouteGroup) Deprecated(sunset time.Time) *RouteGroup {
+	g.info.Deprecated = true
+	g.info.Sunset = sunset
+	return g
+}
+
+// GET method adds the route for HTTP method GET into group.
+func (g *RouteGroup) GET(routePath, target, action string) *RouteGroup {
+	return g.Handle("", ahttp.MethodGet, routePath, target, action)
+}
+
+// HEAD method adds the route for HTTP method HEAD into group.
+func (g *RouteGroup) HEAD(routePath, target, action string) *RouteGroup {
+	return g.Handle("", ahttp.MethodHead, routePath, target, action)
+}
+
+// POST method adds the route for HTTP method POST into group.
+func (g *RouteGroup) POST(routePath, target, action string) *RouteGroup {
+	return g.Handle("", ahttp.MethodPost, routePath, target, action)
+}
+
+// PUT method adds the route for HTTP method PUT into group.
+func (g *RouteGroup) PUT(routePath, target, action string) *RouteGroup {
+	return g.Handle("", ahttp.MethodPut, routePath, target, action)
+}
+
+// PATCH method adds the route for HTTP method PATCH into group.
+func (g *RouteGroup) PATCH(routePath, target, action string) *RouteGroup {
+	return g.Handle("", ahttp.MethodPatch, routePath, target, action)
+}
+
+// DELETE method adds the route for HTTP method DELETE into group.
+func (g *RouteGroup) DELETE(routePath, target, action string) *RouteGroup {
+	return g.Handle("", ahttp.MethodDelete, routePath, target, action)
+}
+
+// OPTIONS method adds the route for HTTP method OPTIONS into group.
+func (g *RouteGroup) OPTIONS(routePath, target, action string) *RouteGroup {
+	return g.Handle("", ahttp.MethodOptions, routePath, target, action)
+}
+
+// WS method adds the WebSocket route into group.
+func (g *RouteGroup) WS(routePath, target, action string) *RouteGroup {
+	return g.Handle("", methodWebSocket, routePath, target, action)
+}
+
+// Handle method adds the route for given name, HTTP method(s), path,
+// controller and action into group. Multiple HTTP methods are comma
+// separated, for e.g.: `POST,PUT`. Path prefix `^` ignores the group path
+// prefix same as routes config.
+//
+// If name is empty, it is derived from controller and action, for e.g.:
+// `User` and `Show` becomes `user_show`. Derived name is suffixed with number
+// if it is taken by another route definition, for e.g.: `user_show_2`. If
+// controller is empty then group controller is used, if action is empty then
+// it is derived from HTTP method.
+//
+// Route with multiple HTTP methods is added for all the methods or none.
+func (g *RouteGroup) Handle(name, method, routePath, target, action string) *RouteGroup {
+	routes, err := g.NewRoutes(name, method, routePath, target, action)
+	if err != nil {
+		return g.setErr(err)
+	}
+	if err = g.domain.AddRoutes(routes); err != nil {
+		return g.setErr(err)
+	}
+	return g
+}
+
+// NewRoutes method creates the routes same as method `Handle`, however
+// routes are not added into domain. Route values can be changed before
+// adding them using method `Domain.AddRoutes`, for e.g.: to add routes of
+// multiple groups at once.
+func (g *RouteGroup) NewRoutes(name, method, routePath, target, action string) ([]*Route, error) {
+	if g.state.err != nil {
+		return nil, g.state.err
+	}
+
+	info := g.info
+	if len(routePath) > 0 && routePath[0] == '^' {
+		routePath = addSlashPrefix(routePath[1:])
+	} else {
+		routePath = path.Join(info.PrefixPath, addSlashPrefix(routePath))
+	}
+	routePath = path.Clean(strings.TrimSpace(routePath))
+
+	method = strings.ToUpper(strings.TrimSpace(method))
+	if ess.IsStrEmpty(target) {
+		target = info.Target
+	}
+	if ess.IsStrEmpty(action) {
+		action = findActionByHTTPMethod(method)
+	}
+
+	// route reference for errors, until name is derived
+	ref := name
+	if ess.IsStrEmpty(ref) {
+		ref = strings.TrimSpace(method + " " + routePath)
+	}
+	if ess.IsStrEmpty(method) {
+		return nil, fmt.Errorf("router: route '%s' method is missing", ref)
+	}
+	if ess.IsStrEmpty(target) {
+		return nil, fmt.Errorf("router: route '%s' controller or websocket is missing", ref)
+	}
+	if ess.IsStrEmpty(action) {
+		return nil, fmt.Errorf("router: route '%s' action is missing or it seems to be multiple HTTP methods", ref)
+	}
+
+	derived := ess.IsStrEmpty(name)
+	if derived {
+		name = strings.ToLower(strings.Replace(target, "/", "_", -1) + "_" + action)
+	}
+	if info.NamespaceNames && len(info.ParentName) > 0 {
+		name = info.ParentName + "." + name
+	}
+
+	actualRoutePath, routeConstraints, err := parseRouteConstraints(name, routePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if derived {
+		name = g.uniqueRouteName(name, &Route{Path: actualRoutePath, Target: target,
+			Action: action, ParentName: info.ParentName})
+	}
+
+	if !info.AuthorizationInfo.isSatisfiable() {
+		return nil, fmt.Errorf("router: route '%s' authorization satisfy is 'both', however roles and permissions is not configured", name)
+	}
+
+	var cors *CORS
+	if info.CORSEnabled {
+		cors = info.CORS
+	}
+
+	maxBodySize, _ := ess.StrToBytes(info.MaxBodySizeStr)
+	routes := newRoutes(Route{
+		Name:              name,
+		Path:              actualRoutePath,
+		Method:            method,
+		Target:            target,
+		Action:            action,
+		ParentName:        info.ParentName,
+		Auth:              info.Auth,
+		MaxBodySize:       maxBodySize,
+		IsAntiCSRFCheck:   info.AntiCSRFCheck,
+		CORS:              cors,
+		Constraints:       routeConstraints,
+		Defaults:          g.defaults,
+		Meta:              info.Meta,
+		Interceptors:      info.Interceptors,
+		Tags:              info.Tags,
+		Owner:             info.Owner,
+		Deprecated:        info.Deprecated,
+		Sunset:            info.Sunset,
+		authorizationInfo: info.AuthorizationInfo,
+	})
+
+	return routes, nil
+}
+
+// Err method returns the first error occurred on the group or it's sub
+// groups otherwise nil.
+func (g *RouteGroup) Err() error {
+	return g.state.err
+}
+
+// uniqueRouteName method returns the given derived route name if it is not
+// taken by another route definition otherwise name suffixed with number.
+func (g *RouteGroup) uniqueRouteName(name string, def *Route) string {
+	uniqueName := name
+	for i := 2; ; i++ {
+		taken := false
+		for _, r := range g.domain.namedRoutes[uniqueName] {
+			if r.Path != def.Path || r.Target != def.Target || r.Action != def.Action ||
+				r.ParentName != def.ParentName {
+				taken = true
+				break
+			}
+		}
+		if !taken {
+			return uniqueName
+		}
+		uniqueName = fmt.Sprintf("%s_%d", name, i)
+	}
+}
+
+func (g *RouteGroup) setErr(err error) *RouteGroup {
+	if g.state.err == nil {
+		g.state.err = err
+	}
+	return g
+}
@@ -0,0 +1,251 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/router source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package router
+
+import (
+	"testing"
+
+	"aahframework.org/ahttp.v0"
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestBuilderAddDomain(t *testing.T) {
+	rtr := &Router{}
+	d := rtr.AddDomain("sample.com", "443")
+	assert.Equal(t, "sample.com", d.Key)
+	assert.Equal(t, "", d.Port)
+	assert.True(t, d.MethodNotAllowed)
+	assert.True(t, d.RedirectTrailingSlash)
+	assert.True(t, d.AutoOptions)
+	assert.True(t, d.AntiCSRFEnabled)
+	assert.False(t, d.IsSubDomain)
+
+	sd := rtr.AddDomain("*.sample.com", "8080")
+	assert.Equal(t, "*.sample.com:8080", sd.Key)
+	assert.True(t, sd.IsSubDomain)
+
+	assert.Equal(t, d, rtr.AddDomain("Sample.com", "80"))
+	assert.Equal(t, 2, len(rtr.Domains))
+	assert.Equal(t, d, rtr.RootDomain())
+	assert.Equal(t, sd, rtr.Lookup("tenant1.sample.com:8080"))
+	assert.Nil(t, rtr.Err())
+
+	// same host on other port is a new domain
+	rtr = &Router{}
+	pd := rtr.AddDomain("sample.com", "8080")
+	d = rtr.AddDomain("sample.com", "80")
+	assert.NotEqual(t, pd, d)
+	assert.Equal(t, "sample.com", d.Key)
+	assert.Equal(t, d, rtr.AddDomain("Sample.com", ""))
+	assert.Equal(t, 2, len(rtr.Domains))
+	assert.Nil(t, rtr.Err())
+
+	// host conflict
+	cd := rtr.AddDomain("sample.com.", "443")
+	assert.Equal(t, 2, len(rtr.Domains))
+	assert.Equal(t, d, rtr.Lookup("sample.com"))
+	assert.Equal(t, "router: domain host value 'sample.com.' conflicts with domain 'sample.com'", rtr.Err().Error())
+	assert.Equal(t, rtr.Err(), cd.Group("/").GET("/", "App", "Index").Err())
+	assert.Nil(t, cd.LookupByName("app_index"))
+}
+
+func TestBuilderRouteGroup(t *testing.T) {
+	rtr := &Router{}
+	d := rtr.AddDomain("localhost", "8080")
+	d.DefaultAuth = "form_auth"
+	d.CORSEnabled = true
+	d.CORS = (&CORS{}).AddOrigins([]string{"*"}).AddAllowMethods(defaultAllowMethods)
+
+	v1 := d.Group("/v1").Auth("jwt").AntiCSRFCheck(false)
+	v1.GET("/users/:id[int]", "User", "Show").
+		Handle("user_update", "POST,PUT", "/users/:id[int]", "User", "Update").
+		WS("/users/:id/events", "UserEvents", "Handle")
+
+	admin := v1.Group("/admin").Name("admin").Controller("admin/Setting").
+		MaxBodySize("10mb").Roles("hasrole(admin)").CORS(nil)
+	admin.GET("/settings", "", "List").
+		POST("/settings", "", "")
+	d.Group("").GET("^/login", "App", "Login")
+	assert.Nil(t, v1.Err())
+
+	// inherited values
+	route := d.LookupByName("user_show")
+	assert.Equal(t, "/v1/users/:id", route.Path)
+	assert.Equal(t, "jwt", route.Auth)
+	assert.False(t, route.IsAntiCSRFCheck)
+	assert.Equal(t, d.CORS, route.CORS)
+	assert.Equal(t, "int", route.Constraints["id"])
+	assert.Equal(t, int64(0), route.MaxBodySize)
+
+	route = d.LookupByNameMethod("user_update", ahttp.MethodPut)
+	assert.Equal(t, "Update", route.Action)
+	assert.Equal(t, int64(5242880), route.MaxBodySize)
+	assert.Equal(t, 2, len(d.RoutesByName("user_update")))
+
+	route = d.LookupByName("userevents_handle")
+	assert.Equal(t, methodWebSocket, route.Method)
+	assert.Nil(t, route.CORS)
+	assert.False(t, route.IsAntiCSRFCheck)
+
+	route = d.LookupByName("admin_setting_list")
+	assert.Equal(t, "/v1/admin/settings", route.Path)
+	assert.Equal(t, "admin/Setting", route.Target)
+	assert.Equal(t, "admin", route.ParentName)
+	assert.Equal(t, "jwt", route.Auth)
+	assert.Nil(t, route.CORS)
+	assert.Equal(t, []string{"admin"}, route.authorizationInfo.Roles["hasrole"])
+
+	route = d.LookupByName("admin_setting_create")
+	assert.Equal(t, ahttp.MethodPost, route.Method)
+	assert.Equal(t, int64(10485760), route.MaxBodySize)
+
+	route = d.LookupByName("app_login")
+	assert.Equal(t, "/login", route.Path)
+	assert.Equal(t, "form_auth", route.Auth)
+	assert.True(t, route.IsAntiCSRFCheck)
+
+	// group values set later are not applied to sub group created earlier
+	assert.Nil(t, d.LookupByName("user_show").authorizationInfo.Roles)
+
+	req := createHTTPRequest("localhost:8080", "/v1/users/10")
+	req.Method = ahttp.MethodGet
+	route, pathParams, _ := d.Lookup(req)
+	assert.Equal(t, "user_show", route.Name)
+	assert.Equal(t, "10", pathParams.Get("id"))
+	assert.Equal(t, "/v1/users/10", d.RouteURL("user_update", 10))
+}
+
+func TestBuilderMaxBodySize(t *testing.T) {
+	appCfg, _ := config.ParseString(`request { max_body_size = "2mb" }`)
+	d := New("", appCfg).AddDomain("localhost", "8080")
+	d.Group("/").POST("/users", "User", "Create")
+	d.Group("/").MaxBodySize("10mb").PUT("/users/:id", "User", "Update")
+	assert.Equal(t, int64(2097152), d.LookupByName("user_create").MaxBodySize)
+	assert.Equal(t, int64(10485760), d.LookupByName("user_update").MaxBodySize)
+
+	// without app config
+	d = (&Router{}).AddDomain("localhost", "8080")
+	d.Group("/").POST("/users", "User", "Create")
+	assert.Equal(t, int64(5242880), d.LookupByName("user_create").MaxBodySize)
+}
+
+func TestBuilderNamespaceNames(t *testing.T) {
+	d := (&Router{}).AddDomain("localhost", "8080")
+	d.NamespaceNames = true
+
+	hotels := d.Group("/hotels").Name("hotels").Controller("Hotel")
+	hotels.Handle("show", ahttp.MethodGet, "/:id", "", "Show")
+	hotels.Group("/:id/rooms").Name("rooms").Controller("Room").
+		Handle("show", ahttp.MethodGet, "/:number", "", "Show")
+	assert.Nil(t, hotels.Err())
+
+	assert.Equal(t, "/hotels/:id", d.LookupByName("hotels.show").Path)
+	route := d.LookupByName("hotels.rooms.show")
+	assert.Equal(t, "/hotels/:id/rooms/:number", route.Path)
+	assert.Equal(t, "hotels.rooms", route.ParentName)
+}
+
+func TestBuilderErrors(t *testing.T) {
+	d := (&Router{}).AddDomain("localhost", "8080")
+
+	g := d.Group("/v1").MaxBodySize("10 apples")
+	assert.Equal(t, "router: group '/v1' max body size '10 apples' is not a valid size unit", g.Err().Error())
+	g.GET("/users", "User", "List")
+	assert.Nil(t, d.LookupByName("user_list"))
+
+	g = d.Group("/v1")
+	g.GET("/users", "", "List")
+	assert.Equal(t, "router: route 'GET /v1/users' controller or websocket is missing", g.Err().Error())
+
+	g = d.Group("/v1")
+	g.Handle("users", "POST,PUT", "/users", "User", "")
+	assert.Equal(t, "router: route 'users' action is missing or it seems to be multiple HTTP methods", g.Err().Error())
+
+	g = d.Group("/v1").Roles("hasrole(admin, manager)")
+	assert.Equal(t, "router: group '/v1' authorization roles at index 1 have func 'hasrole' supports only one input param", g.Err().Error())
+
+	g = d.Group("/v1").Satisfy("both").Roles("hasrole(admin)")
+	g.GET("/users", "User", "List")
+	assert.Equal(t, "router: route 'user_list' authorization satisfy is 'both', however roles and permissions is not configured", g.Err().Error())
+
+	g = d.Group("/v1")
+	g.Handle("users", ahttp.MethodGet, "/users", "User", "List").
+		Group("/admin").Handle("users", ahttp.MethodGet, "/users", "User", "List")
+	assert.NotNil(t, g.Err())
+	assert.Equal(t, "router: duplicate route name 'users', defined as [GET /v1/users => User.List] and [GET /v1/admin/users => User.List]", g.Err().Error())
+
+	// multiple HTTP methods route is added for all the methods or none
+	g = d.Group("/v1")
+	g.Handle("user_edit", "PUT,GET", "/users", "User", "Edit")
+	assert.Equal(t, "a value is already registered for path '/v1/users'", g.Err().Error())
+	assert.Nil(t, d.LookupByName("user_edit"))
+	req := createHTTPRequest("localhost:8080", "/v1/users")
+	req.Method = ahttp.MethodPut
+	route, _, _ := d.Lookup(req)
+	assert.Nil(t, route)
+}
+
+func TestBuilderDerivedNames(t *testing.T) {
+	d := (&Router{}).AddDomain("localhost", "8080")
+
+	// same controller and action on other paths
+	g := d.Group("/")
+	g.GET("/users/:id", "User", "Show").
+		HEAD("/users/:id", "User", "Show").
+		GET("/admin/users/:id", "User", "Show").
+		GET("/v2/users/:id", "User", "Show")
+	assert.Nil(t, g.Err())
+
+	assert.Equal(t, 2, len(d.RoutesByName("user_show")))
+	assert.Equal(t, "/users/:id", d.LookupByName("user_show").Path)
+	assert.Equal(t, "/admin/users/:id", d.LookupByName("user_show_2").Path)
+	assert.Equal(t, "/v2/users/:id", d.LookupByName("user_show_3").Path)
+
+	// same route again is a duplicate
+	g.GET("/admin/users/:id", "User", "Show")
+	assert.Equal(t, "router: duplicate route name 'user_show_2', defined as [GET /admin/users/:id => User.Show] and [GET /admin/users/:id => User.Show]", g.Err().Error())
+}
+
+func TestBuilderDefaults(t *testing.T) {
+	d := (&Router{}).AddDomain("localhost", "8080")
+
+	g := d.Group("/reports").Controller("Report")
+	g.Defaults(map[string]string{"period": "month"}).
+		GET("/:period[oneof=day week month]?", "", "Show")
+	g.GET("/:year/summary", "", "Summary")
+	assert.Nil(t, g.Err())
+
+	req := createHTTPRequest("localhost:8080", "/reports")
+	req.Method = ahttp.MethodGet
+	route, pathParams, _ := d.Lookup(req)
+	assert.Equal(t, "report_show", route.Name)
+	assert.Equal(t, "month", pathParams.Get("period"))
+	assert.Nil(t, d.LookupByName("report_summary").Defaults)
+
+	g = d.Group("/").Defaults(map[string]string{"period": "month"})
+	g.GET("/stats", "Stat", "Show")
+	assert.Equal(t, "'stat_show.defaults' has 'period' which is not an optional param in path => '/stats'", g.Err().Error())
+}
+
+func TestBuilderNewRoutes(t *testing.T) {
+	d := (&Router{}).AddDomain("localhost", "8080")
+
+	g := d.Group("/v1").Controller("User")
+	routes, err := g.NewRoutes("user_update", "POST,PUT", "/users/:id[int]", "", "Update")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(routes))
+	assert.Equal(t, "/v1/users/:id", routes[1].Path)
+	assert.Equal(t, "int", routes[1].Constraints["id"])
+	assert.Nil(t, d.LookupByName("user_update"))
+
+	routes[0].Description = "Update user"
+	assert.Nil(t, d.AddRoutes(routes))
+	assert.Equal(t, "Update user", d.LookupByNameMethod("user_update", ahttp.MethodPost).Description)
+
+	_, err = g.NewRoutes("", "", "/users", "", "List")
+	assert.Equal(t, "router: route '/v1/users' method is missing", err.Error())
+	assert.Nil(t, g.Err())
+}
@@ -0,0 +1,239 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/router source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package router
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"aahframework.org/valpar.v0"
+)
+
+// ConstraintFunc type is used to validate route path parameter value.
+// It returns true if the value satisfies the constraint otherwise false.
+type ConstraintFunc func(value string) bool
+
+// constraint holds the compiled route path parameter constraint, it's
+// expression and rank. Rank is used to order the param edges in the routing
+// tree, lower rank is tried first.
+type constraint struct {
+	rank int
+	expr string
+	fn   ConstraintFunc
+}
+
+// builtinConstraint holds the router built-in constraint, these are the
+// constraints valpar does not offer for path parameter values.
+type builtinConstraint struct {
+	name string
+	fn   ConstraintFunc
+}
+
+var (
+	// constraints holds the constraints registered via `RegisterConstraint`.
+	constraints = map[string]ConstraintFunc{}
+
+	// builtinConstraints holds the router built-in constraints ordered from
+	// narrowest to widest accepted values, it's position defines the rank.
+	builtinConstraints = []builtinConstraint{
+		{name: "uint", fn: isUint},
+		{name: "int", fn: isInt},
+		{name: "date", fn: isDate},
+		{name: "slug", fn: regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`).MatchString},
+	}
+)
+
+// RegisterConstraint method registers the given constraint func by name to use
+// in the route path parameter constraints. For e.g.: `/users/:id[hexcolor]`.
+// Existing constraint with same name gets replaced, including built-in and
+// valpar validation tags.
+//
+// Note: It is not concurrency-safe, register constraints during application
+// initialization.
+func RegisterConstraint(name string, fn ConstraintFunc) error {
+	name = strings.TrimSpace(name)
+	if len(name) == 0 || strings.ContainsAny(name, "=(),[]|") {
+		return fmt.Errorf("router: invalid constraint name '%s'", name)
+	}
+	if fn == nil {
+		return errors.New("router: constraint func is nil")
+	}
+	constraints[name] = fn
+	return nil
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Unexported methods
+//______________________________________________________________________________
+
+// compileConstraint method compiles the given constraint expression into
+// single constraint func. Expression is comma separated rules, all the rules
+// have to be satisfied. Rule is resolved in the order of -
+//  1. regular expression, for e.g.: `regex(^[a-z]{2}-[0-9]+$)`
+//  2. constraint registered via `RegisterConstraint`
+//  3. router built-in constraint `uint`, `int`, `date` or `slug`
+//  4. valpar validation tag, for e.g.: `uuid`, `alpha`, `gt=1`, `oneof=a b`
+//
+// Valpar tags are validated once here, so unknown tag or invalid tag argument
+// fails the route load. Note: valpar validates the path param value as
+// string, so `gt`, `lt`, `min`, `max`, etc. compares the value length.
+//
+// Rank of the rule is -
+//   - 0 for regular expression and registered constraint
+//   - 1 + position in `builtinConstraints` for built-in constraint
+//   - 1 + len(builtinConstraints) for valpar tag
+//
+// Constraint rank is the lowest rank of it's rules.
+func compileConstraint(expr string) (*constraint, error) {
+	c := &constraint{expr: expr, rank: len(builtinConstraints) + 1}
+	var rules []ConstraintFunc
+	var tags []string
+	for _, rule := range splitConstraintRules(expr) {
+		fn, rank, err := compileConstraintRule(rule)
+		if err != nil {
+			return nil, err
+		}
+		if fn == nil {
+			tags = append(tags, rule)
+			continue
+		}
+		if rank < c.rank {
+			c.rank = rank
+		}
+		rules = append(rules, fn)
+	}
+
+	if len(tags) > 0 {
+		rules = append(rules, valparConstraint(strings.Join(tags, ",")))
+	}
+
+	if len(rules) == 1 {
+		c.fn = rules[0]
+		return c, nil
+	}
+
+	c.fn = func(value string) bool {
+		for _, fn := range rules {
+			if !fn(value) {
+				return false
+			}
+		}
+		return true
+	}
+	return c, nil
+}
+
+func (c *constraint) isEqual(o *constraint) bool {
+	if c == nil || o == nil {
+		return c == o
+	}
+	return c.expr == o.expr
+}
+
+// isSatisfied method validates the given value against the constraint,
+// escaped value is unescaped before the validation.
+func (c *constraint) isSatisfied(value string, escaped bool) bool {
+	if escaped {
+		value = unescapePathValue(value)
+	}
+	return c.fn(value)
+}
+
+// compileConstraintRule method returns the constraint func and rank of the
+// given rule. It returns nil func for the valpar validation tag after
+// validating it.
+func compileConstraintRule(rule string) (ConstraintFunc, int, error) {
+	if strings.HasPrefix(rule, "regex(") && strings.HasSuffix(rule, ")") {
+		// regex is matched against the entire value
+		re, err := regexp.Compile("^(?:" + rule[6:len(rule)-1] + ")$")
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid regex constraint '%s': %s", rule, err)
+		}
+		return re.MatchString, 0, nil
+	}
+
+	if fn, found := constraints[rule]; found {
+		return fn, 0, nil
+	}
+
+	for idx, b := range builtinConstraints {
+		if b.name == rule {
+			return b.fn, idx + 1, nil
+		}
+	}
+
+	return nil, 0, checkValparTag(rule)
+}
+
+// checkValparTag method checks the given valpar validation tag is known and
+// it's argument is valid. Valpar panics on those, so it's recovered here.
+func checkValparTag(tag string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if strings.HasPrefix(fmt.Sprint(r), "Undefined validation function") {
+				err = fmt.Errorf("unknown constraint '%s'", tag)
+			} else {
+				err = fmt.Errorf("invalid constraint '%s': %v", tag, r)
+			}
+		}
+	}()
+	_ = valpar.ValidateValue("", tag)
+	return
+}
+
+func valparConstraint(tags string) ConstraintFunc {
+	return func(value string) bool {
+		return valpar.ValidateValue(value, tags)
+	}
+}
+
+// splitConstraintRules method splits the constraint expression by comma,
+// comma within parentheses is not treated as separator.
+func splitConstraintRules(expr string) []string {
+	var rules []string
+	depth, start := 0, 0
+	for i := 0; i < len(expr); i++ {
+		switch expr[i] {
+		case '(':
+			depth++
+		case ')':
+			if depth > 0 {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				rules = appendRule(rules, expr[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return appendRule(rules, expr[start:])
+}
+
+func appendRule(rules []string, rule string) []string {
+	if rule = strings.TrimSpace(rule); len(rule) > 0 {
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+func isInt(value string) bool {
+	_, err := strconv.ParseInt(value, 10, 64)
+	return err == nil
+}
+
+func isUint(value string) bool {
+	_, err := strconv.ParseUint(value, 10, 64)
+	return err == nil
+}
+
+func isDate(value string) bool {
+	_, err := time.Parse("2006-01-02", value)
+	return err == nil
+}
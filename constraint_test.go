@@ -0,0 +1,139 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/router source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package router
+
+import (
+	"strings"
+	"testing"
+
+	"aahframework.org/test.v0/assert"
+)
+
+func TestRouteConstraintBuiltins(t *testing.T) {
+	testcases := []struct {
+		expr    string
+		valid   []string
+		invalid []string
+	}{
+		{expr: "int", valid: []string{"123", "-45"}, invalid: []string{"12a", "", "1.5"}},
+		{expr: "uint", valid: []string{"0", "123"}, invalid: []string{"-1", "abc"}},
+		{expr: "slug", valid: []string{"hello-world", "aah2"}, invalid: []string{"Hello-World", "hello--world", "-hello"}},
+		{expr: "date", valid: []string{"2018-07-28"}, invalid: []string{"2018-13-01", "28-07-2018"}},
+		{expr: "regex(^[a-z]{2}-[0-9]{1,3}$)", valid: []string{"ab-1", "xy-123"}, invalid: []string{"ab-1234", "a-1"}},
+		{expr: "regex([a-z]+)", valid: []string{"abc"}, invalid: []string{"abc1"}},
+
+		// valpar validation tags, value is validated as string
+		{expr: "uuid", valid: []string{"5de80bf1-b2c7-4c6e-b0bc-e47758b7d817"}, invalid: []string{"5de80bf1b2c74c6eb0bce47758b7d817", "dshkjfdgf"}},
+		{expr: "alpha", valid: []string{"abcXYZ"}, invalid: []string{"abc1", "äbc"}},
+		{expr: "alphanum", valid: []string{"abc123"}, invalid: []string{"abc-123"}},
+		{expr: "gt=1,lt=4", valid: []string{"ab", "abc"}, invalid: []string{"a", "abcd"}},
+		{expr: "min=2,max=4", valid: []string{"ab", "abcd"}, invalid: []string{"a", "abcde"}},
+		{expr: "len=3", valid: []string{"abc", "äbc"}, invalid: []string{"ab"}},
+		{expr: "oneof=blue green red,alpha", valid: []string{"green"}, invalid: []string{"yellow"}},
+		{expr: "required,numeric", valid: []string{"-1.5", "42"}, invalid: []string{"", "abc"}},
+
+		// router built-in and valpar tags together
+		{expr: "int,max=3", valid: []string{"123", "-12"}, invalid: []string{"1234", "abc"}},
+		{expr: "slug,startswith=v", valid: []string{"v1-x"}, invalid: []string{"x-v1", "V1"}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.expr, func(t *testing.T) {
+			c, err := compileConstraint(tc.expr)
+			assert.FailNowOnError(t, err, "")
+			for _, v := range tc.valid {
+				assert.Truef(t, c.fn(v), "expected '%s' to be valid", v)
+			}
+			for _, v := range tc.invalid {
+				assert.Falsef(t, c.fn(v), "expected '%s' to be invalid", v)
+			}
+		})
+	}
+}
+
+func TestRouteConstraintCompileError(t *testing.T) {
+	testcases := []struct {
+		expr, err string
+	}{
+		{expr: "intt", err: "unknown constraint 'intt'"},
+		{expr: "int,notexists", err: "unknown constraint 'notexists'"},
+		{expr: "between=1", err: "unknown constraint 'between=1'"},
+		{expr: "int,gt=abc", err: "invalid constraint 'gt=abc': "},
+		{expr: "max=ten", err: "invalid constraint 'max=ten': "},
+		{expr: "regex([a-z)", err: "invalid regex constraint 'regex([a-z)'"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.expr, func(t *testing.T) {
+			_, err := compileConstraint(tc.expr)
+			assert.NotNil(t, err)
+			assert.Truef(t, strings.HasPrefix(err.Error(), tc.err), "got '%v'", err)
+		})
+	}
+}
+
+func TestRouteConstraintRegister(t *testing.T) {
+	err := RegisterConstraint("hexcolor", func(value string) bool {
+		return len(value) == 6 && strings.Trim(value, "0123456789abcdef") == ""
+	})
+	assert.Nil(t, err)
+	defer delete(constraints, "hexcolor")
+
+	c, err := compileConstraint("hexcolor")
+	assert.Nil(t, err)
+	assert.True(t, c.fn("ff00aa"))
+	assert.False(t, c.fn("zz00aa"))
+	assert.Equal(t, 0, c.rank)
+
+	// registered constraint replaces the valpar tag
+	err = RegisterConstraint("alpha", func(value string) bool { return value == "aah" })
+	assert.Nil(t, err)
+	defer delete(constraints, "alpha")
+
+	c, err = compileConstraint("alpha")
+	assert.Nil(t, err)
+	assert.True(t, c.fn("aah"))
+	assert.False(t, c.fn("abc"))
+
+	err = RegisterConstraint(" ", isInt)
+	assert.Equal(t, "router: invalid constraint name ''", err.Error())
+
+	err = RegisterConstraint("regex(x)", isInt)
+	assert.Equal(t, "router: invalid constraint name 'regex(x)'", err.Error())
+
+	err = RegisterConstraint("nilfunc", nil)
+	assert.Equal(t, "router: constraint func is nil", err.Error())
+}
+
+func TestRouteConstraintRank(t *testing.T) {
+	testcases := []struct {
+		expr string
+		rank int
+	}{
+		{expr: "regex([a-z]+)", rank: 0},
+		{expr: "uint", rank: 1},
+		{expr: "int", rank: 2},
+		{expr: "date", rank: 3},
+		{expr: "slug", rank: 4},
+		{expr: "uuid", rank: 5},
+		{expr: "alpha,max=10", rank: 5},
+		{expr: "max=10,int", rank: 2},
+		{expr: "slug,regex([a-z]+)", rank: 0},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.expr, func(t *testing.T) {
+			c, err := compileConstraint(tc.expr)
+			assert.FailNowOnError(t, err, "")
+			assert.Equal(t, tc.rank, c.rank)
+		})
+	}
+}
+
+func TestRouteConstraintLoadError(t *testing.T) {
+	_, err := createRouter("routes-constraint-error.conf")
+	assert.NotNil(t, err)
+	assert.Equal(t, "'show_user.path' has unknown constraint 'intt' on param 'id' in path => '/users/:id'", err.Error())
+}
@@ -9,7 +9,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
-	"path"
+	"sort"
 	"strings"
 
 	"aahframework.org/ahttp.v0"
@@ -25,8 +25,15 @@ import (
 // Domain is used to hold domain related routes and it's route configuration
 type Domain struct {
 	IsSubDomain           bool
+	AnyPort               bool
+	IsDefault             bool
+	CanonicalRedirect     bool
 	MethodNotAllowed      bool
 	RedirectTrailingSlash bool
+	RedirectFixedPath     bool
+	CaseInsensitiveLookup bool
+	UseRawPath            bool
+	NamespaceNames        bool
 	AutoOptions           bool
 	AntiCSRFEnabled       bool
 	CORSEnabled           bool
@@ -34,46 +41,153 @@ type Domain struct {
 	Name                  string
 	Host                  string
 	Port                  string
+	Scheme                string
 	DefaultAuth           string
+	Aliases               []string
 	CORS                  *CORS
 	trees                 map[string]*node
 	routes                map[string]*Route
+	namedRoutes           map[string][]*Route
+	hostParams            []hostParam
+	maxBodySizeStr        string
+	err                   error
 }
 
 // Lookup method looks up route if found it returns route, path parameters,
 // redirect trailing slash indicator for given `ahttp.Request` by domain
 // and request URI otherwise returns nil and false.
+//
+// Route path parameter constraints are validated too, if validation fails
+// it is treated as route not found. Use method `LookupWithError` to
+// distinguish constraint failure from not found.
+//
+// If domain `case_insensitive_lookup` is enabled then request path is matched
+// case-insensitively when exact match is not found.
+//
+// If domain host has host params, for e.g.: `:tenant.app.com` then host param
+// values of request host are returned along with path parameters.
+//
+// If domain `use_raw_path` is enabled then request escaped path is matched, so
+// escaped slash `%2F` stays within path parameter value. Each path parameter
+// value is unescaped individually.
 func (d *Domain) Lookup(req *http.Request) (*Route, ahttp.PathParams, bool) {
-	// HTTP method override support
-	overrideMethod := req.Header.Get(ahttp.HeaderXHTTPMethodOverride)
-	if len(overrideMethod) > 0 && req.Method == ahttp.MethodPost {
-		req.Method = overrideMethod
+	route, pathParams, rts, err := d.LookupWithError(req)
+	if err != nil {
+		return nil, nil, false
 	}
+	return route, pathParams, rts
+}
 
-	// get route tree for request method
-	tree, found := d.trees[req.Method]
-	if !found {
-		// get route tree for CORS access control method
-		if req.Method == ahttp.MethodOptions && d.CORSEnabled {
-			tree, found = d.trees[req.Header.Get(ahttp.HeaderAccessControlRequestMethod)]
-		}
+// LookupWithError method is same as `Lookup` method additionally it validates
+// the path parameter values against the route constraints. If validation fails
+// it returns found route, path parameters and error `ErrRouteConstraintFailed`.
+func (d *Domain) LookupWithError(req *http.Request) (*Route, ahttp.PathParams, bool, error) {
+	tree := d.lookupTree(req)
+	if tree == nil {
+		return nil, nil, false, nil
+	}
 
-		if !found {
-			return nil, nil, false
+	reqPath, flags := d.requestPath(req)
+	value, pathParams, rts, err := tree.find(reqPath, flags)
+	if value == nil && !rts && err == nil && d.CaseInsensitiveLookup {
+		if ciPath, found, _ := tree.findCaseInsensitive(reqPath, false); found {
+			value, pathParams, err = tree.lookup(ciPath, flags|checkConstraints)
+		} else {
+			_, rts, _ = tree.findCaseInsensitive(reqPath, true)
 		}
 	}
 
-	route, pathParams, rts, err := tree.find(req.URL.Path)
-	if route != nil && err == nil {
-		return route.(*Route), pathParams, rts
+	if value != nil && err == nil {
+		route := value.(*Route)
+		return route, d.addHostParams(req.Host, route.addDefaults(pathParams)), rts, nil
 	} else if rts { // possible Redirect Trailing Slash
-		return nil, nil, rts
+		return nil, nil, rts, nil
+	}
+
+	// Route path matches however path parameter constraints failed
+	if value, pathParams, err = tree.lookup(reqPath, flags); value != nil && err == nil {
+		route := value.(*Route)
+		return route, d.addHostParams(req.Host, route.addDefaults(pathParams)), false, ErrRouteConstraintFailed
+	}
+
+	return nil, nil, false, nil
+}
+
+// LookupInto method is same as `Lookup` method however path parameters are
+// captured into given params buffer instead of allocating `ahttp.PathParams`.
+// Buffer is reset before lookup. It performs zero allocations when route is
+// found by exact match, reuse the buffer across the lookups. For e.g.:
+//
+//	pb := router.AcquireParamsBuffer()
+//	defer router.ReleaseParamsBuffer(pb)
+//	route, rts := domain.LookupInto(req, pb)
+func (d *Domain) LookupInto(req *http.Request, pb *ParamsBuffer) (*Route, bool) {
+	pb.Reset()
+	tree := d.lookupTree(req)
+	if tree == nil {
+		return nil, false
 	}
 
-	return nil, nil, false
+	reqPath, flags := d.requestPath(req)
+	pb.grow(int(tree.maxParams) + len(d.hostParams))
+	if value, err := tree.match(reqPath, &pb.params, flags|checkConstraints); value != nil && err == nil {
+		route := value.(*Route)
+		if flags&escapedPath != 0 {
+			for i := range pb.params {
+				pb.params[i].value = unescapePathValue(pb.params[i].value)
+			}
+		}
+		pb.grow(len(pb.params) + len(route.Defaults) + len(d.hostParams))
+		for paramName, value := range route.Defaults {
+			pb.add(paramName, value)
+		}
+		if len(d.hostParams) > 0 {
+			hostname, _ := normalizeHost(req.Host)
+			for _, hp := range d.hostParams {
+				if value, found := hostLabel(hostname, hp.index); found {
+					pb.add(hp.name, value)
+				}
+			}
+		}
+		return route, false
+	}
+	pb.Reset()
+
+	// exact match not found, fallback to `Lookup` for case-insensitive
+	// lookup and trailing slash redirect indicator
+	route, pathParams, rts := d.Lookup(req)
+	for paramName, value := range pathParams {
+		pb.add(paramName, value)
+	}
+	return route, rts
+}
+
+// LookupFixedPath method returns the canonical path for the given request
+// path if route exists for the canonical path otherwise empty string and false.
+// Request path is cleaned using `CleanPath`, then looked up case-insensitively
+// and trailing slash is fixed if domain `redirect_trailing_slash` is enabled.
+// For e.g.: `/Hotels//5/` to `/hotels/5`.
+//
+// Typically it is used along with domain `redirect_fixed_path` option to
+// redirect the request to canonical path when route is not found.
+func (d *Domain) LookupFixedPath(req *http.Request) (string, bool) {
+	tree := d.lookupTree(req)
+	if tree == nil {
+		return "", false
+	}
+
+	reqPath, _ := d.requestPath(req)
+	fixedPath, found, err := tree.findCaseInsensitive(CleanPath(reqPath), d.RedirectTrailingSlash)
+	if !found || err != nil {
+		return "", false
+	}
+
+	return fixedPath, true
 }
 
 // LookupByName method returns the route for given route name otherwise nil.
+// For multiple HTTP methods route, it returns the route of first method in
+// the configuration, use method `LookupByNameMethod` for specific method.
 func (d *Domain) LookupByName(name string) *Route {
 	if route, found := d.routes[name]; found {
 		return route
@@ -81,10 +195,44 @@ func (d *Domain) LookupByName(name string) *Route {
 	return nil
 }
 
+// LookupByNameMethod method returns the route for given route name and HTTP
+// method otherwise nil.
+func (d *Domain) LookupByNameMethod(name, method string) *Route {
+	method = strings.ToUpper(method)
+	for _, route := range d.namedRoutes[name] {
+		if route.Method == method {
+			return route
+		}
+	}
+	return nil
+}
+
+// RoutesByName method returns all the routes for given route name, route
+// configured with multiple HTTP methods has one route per method.
+func (d *Domain) RoutesByName(name string) []*Route {
+	return d.namedRoutes[name]
+}
+
+// Routes method returns all the routes of domain, ordered by route path and
+// HTTP method.
+func (d *Domain) Routes() []*Route {
+	var routes []*Route
+	for _, r := range d.namedRoutes {
+		routes = append(routes, r...)
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path == routes[j].Path {
+			return routes[i].Method < routes[j].Method
+		}
+		return routes[i].Path < routes[j].Path
+	})
+	return routes
+}
+
 // AddRoute method adds the given route into domain routing tree.
 func (d *Domain) AddRoute(route *Route) error {
-	if ess.IsStrEmpty(route.Method) {
-		return errors.New("router: method value is empty")
+	if err := d.checkRoute(route, nil); err != nil {
+		return err
 	}
 
 	tree := d.trees[route.Method]
@@ -93,11 +241,43 @@ func (d *Domain) AddRoute(route *Route) error {
 		d.trees[route.Method] = tree
 	}
 
-	if err := tree.add(route.Path, route); err != nil {
+	if err := tree.insert(route.Path, route, route.constraints); err != nil {
 		return err
 	}
 
-	d.routes[route.Name] = route
+	d.addNamedRoute(route)
+	return nil
+}
+
+// AddRoutes method adds the given routes into domain routing tree, either
+// all the routes are added or none of them. Routing trees are updated only
+// after all the routes are inserted successfully.
+func (d *Domain) AddRoutes(routes []*Route) error {
+	trees := make(map[string]*node)
+	for idx, route := range routes {
+		if err := d.checkRoute(route, routes[:idx]); err != nil {
+			return err
+		}
+
+		tree := trees[route.Method]
+		if tree == nil {
+			if tree = d.trees[route.Method].clone(); tree == nil {
+				tree = new(node)
+			}
+			trees[route.Method] = tree
+		}
+
+		if err := tree.insert(route.Path, route, route.constraints); err != nil {
+			return err
+		}
+	}
+
+	for method, tree := range trees {
+		d.trees[method] = tree
+	}
+	for _, route := range routes {
+		d.addNamedRoute(route)
+	}
 	return nil
 }
 
@@ -119,7 +299,7 @@ func (d *Domain) Allowed(requestMethod, path string) (allowed string) {
 				continue
 			}
 
-			value, _, _, _ := d.trees[method].find(path)
+			value, _, _, _ := d.trees[method].find(path, 0)
 			if value != nil {
 				// add request method to list of allowed methods
 				allowed = suffixCommaValue(allowed, method)
@@ -132,120 +312,455 @@ func (d *Domain) Allowed(requestMethod, path string) (allowed string) {
 
 // RouteURLNamedArgs composes reverse URL by route name and key-value pair arguments.
 // Additional key-value pairs composed as URL query string.
-// If error occurs then method logs it and returns empty string.
+// If error occurs then method logs it and returns empty string. Use method
+// `BuildURL` to get the error.
 func (d *Domain) RouteURLNamedArgs(routeName string, args map[string]interface{}) string {
+	reverseURL, err := d.BuildURL(routeName, args)
+	if err != nil {
+		log.Error(err)
+		return ""
+	}
+	return reverseURL
+}
+
+// RouteURL method composes route reverse URL for given route and
+// arguments based on index order. If error occurs then method logs it
+// and returns empty string. Use method `RouteURLE` to get the error.
+func (d *Domain) RouteURL(routeName string, args ...interface{}) string {
+	reverseURL, err := d.RouteURLE(routeName, args...)
+	if err != nil {
+		log.Error(err)
+		return ""
+	}
+	return reverseURL
+}
+
+// BuildURL method composes reverse URL by route name and key-value pair
+// arguments. Additional key-value pairs composed as URL query string.
+// It returns `*RouteURLError` on error, which holds one of the errors
+// `ErrRouteNotFound`, `ErrMissingParam` and `ErrConstraintViolation`.
+//
+// Argument values are formatted using `encoding.TextMarshaler`, `fmt.Stringer`
+// or `%v` in that order and escaped per path segment. Value which changes the
+// path structure such as empty, `.`, `..` or having `/` is a constraint
+// violation.
+func (d *Domain) BuildURL(routeName string, args map[string]interface{}) (string, error) {
 	route, found := d.routes[routeName]
 	if !found {
-		log.Errorf("route name '%v' not found", routeName)
-		return ""
+		return "", &RouteURLError{Route: routeName, Err: ErrRouteNotFound}
+	}
+
+	if countParams(route.Path) == 0 && len(args) == 0 { // static URLs or no path params
+		return route.Path, nil
+	}
+
+	// compose URL with values
+	consumed := make(map[string]bool)
+	reverseURL, err := reverseRoutePath(route, d.UseRawPath, func(name string) (interface{}, bool) {
+		arg, found := args[name]
+		consumed[name] = found
+		return arg, found
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// add remaining params into URL Query parameters, if any
+	if len(args) > 0 {
+		urlValues := url.Values{}
+
+		for k, v := range args {
+			if consumed[k] {
+				continue
+			}
+			value, err := formatPathValue(v)
+			if err != nil {
+				return "", &RouteURLError{Route: routeName, Param: k, Err: ErrConstraintViolation, Reason: err.Error()}
+			}
+			urlValues.Add(k, value)
+		}
+
+		if len(urlValues) > 0 {
+			reverseURL = fmt.Sprintf("%s?%s", reverseURL, urlValues.Encode())
+		}
+	}
+
+	return reverseURL, nil
+}
+
+// RouteURLE method composes route reverse URL for given route and arguments
+// based on index order. It returns `*RouteURLError` on error, which holds one
+// of the errors `ErrRouteNotFound`, `ErrMissingParam`, `ErrTooManyParams` and
+// `ErrConstraintViolation`. Argument values are formatted and escaped same as
+// `BuildURL`.
+func (d *Domain) RouteURLE(routeName string, args ...interface{}) (string, error) {
+	route, found := d.routes[routeName]
+	if !found {
+		return "", &RouteURLError{Route: routeName, Err: ErrRouteNotFound}
 	}
 
 	argsLen := len(args)
-	pathParamCnt := countParams(route.Path)
+	pathParamCnt := int(countParams(route.Path))
 	if pathParamCnt == 0 && argsLen == 0 { // static URLs or no path params
-		return route.Path
+		return route.Path, nil
 	}
 
-	if argsLen < int(pathParamCnt) { // not enough arguments suppiled
-		log.Errorf("not enough arguments, path: '%v' params count: %v, suppiled values count: %v",
-			route.Path, pathParamCnt, argsLen)
-		return ""
+	// too many arguments
+	if argsLen > pathParamCnt {
+		return "", &RouteURLError{Route: routeName, Err: ErrTooManyParams,
+			Reason: fmt.Sprintf("path '%s' params count %d, supplied values count %d", route.Path, pathParamCnt, argsLen)}
 	}
 
 	// compose URL with values
-	reverseURL := "/"
-	for _, segment := range strings.Split(route.Path, "/")[1:] {
-		if len(segment) == 0 {
+	idx := 0
+	return reverseRoutePath(route, d.UseRawPath, func(name string) (interface{}, bool) {
+		if idx >= argsLen {
+			return nil, false
+		}
+		idx++
+		return args[idx-1], true
+	})
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Domain unexpoted methods
+//___________________________________
+
+// reverseRoutePath composes the URL path for the given route, path param
+// values are obtained from func `valueOf` in the order of params. Each value
+// is formatted, validated against the param constraint and escaped, value
+// with '/' is allowed only for catch-all or if `rawPath` is true. Catch-all
+// value is escaped per path segment, so slashes are retained. Trailing
+// optional params are omitted if value is not supplied or equals to the
+// default value.
+func reverseRoutePath(route *Route, rawPath bool, valueOf func(name string) (interface{}, bool)) (string, error) {
+	tokens, err := parsePathTokens(route.Path)
+	if err != nil {
+		return "", err
+	}
+
+	reverseURL := make([]byte, 0, len(route.Path))
+	omitFrom, omitParam := -1, ""
+	for _, t := range tokens {
+		if t.nType == static {
+			reverseURL = append(reverseURL, t.path...)
 			continue
 		}
 
-		if segment[0] == paramByte || segment[0] == wildByte {
-			argName := segment[1:]
-			if arg, found := args[argName]; found {
-				reverseURL = path.Join(reverseURL, fmt.Sprintf("%v", arg))
-				delete(args, argName)
+		name := strings.TrimLeft(t.path, "/:*")
+		arg, found := valueOf(name)
+		var value string
+		if found {
+			if value, err = formatPathValue(arg); err != nil {
+				return "", &RouteURLError{Route: route.Name, Param: name, Err: ErrConstraintViolation, Reason: err.Error()}
+			}
+		}
+
+		if t.optional {
+			defaultValue, hasDefault := route.Defaults[name]
+			if !found || (hasDefault && value == defaultValue) {
+				if omitFrom == -1 {
+					omitFrom = len(reverseURL) - 1
+				}
+				if !found && !hasDefault && len(omitParam) == 0 {
+					omitParam = name
+				}
+				reverseURL = append(reverseURL, escapePathValue(defaultValue, false)...)
 				continue
 			}
+		} else if !found {
+			return "", &RouteURLError{Route: route.Name, Param: name, Err: ErrMissingParam}
+		}
+
+		// optional param value is supplied, preceding omitted
+		// optional params must have default value
+		if len(omitParam) > 0 {
+			return "", &RouteURLError{Route: route.Name, Param: omitParam, Err: ErrMissingParam}
+		}
+		omitFrom = -1
+
+		if err = validatePathValue(value, t.nType == catchAll, rawPath); err != nil {
+			return "", &RouteURLError{Route: route.Name, Param: name, Err: ErrConstraintViolation, Reason: err.Error()}
+		}
+
+		if t.nType == catchAll {
+			value = strings.TrimPrefix(value, SlashString)
+		}
 
-			log.Errorf("'%v' param not found in given map", segment[1:])
-			return ""
+		if c := route.constraints[name]; c != nil && !c.fn(value) {
+			return "", &RouteURLError{Route: route.Name, Param: name, Err: ErrConstraintViolation,
+				Reason: fmt.Sprintf("value '%s' does not satisfy constraint '%s'", value, c.expr)}
 		}
 
-		reverseURL = path.Join(reverseURL, segment)
+		if t.nType == catchAll {
+			value = SlashString + escapePathValue(value, true)
+		} else {
+			value = escapePathValue(value, false)
+		}
+		reverseURL = append(reverseURL, value...)
 	}
 
-	// add remaining params into URL Query parameters, if any
-	if len(args) > 0 {
-		urlValues := url.Values{}
+	if omitFrom > -1 {
+		reverseURL = reverseURL[:omitFrom]
+	}
 
-		for k, v := range args {
-			urlValues.Add(k, fmt.Sprintf("%v", v))
+	// path is not cleaned, it would drop the trailing slash of the path
+	// and catch-all value
+	if len(reverseURL) == 0 {
+		return SlashString, nil
+	}
+	return string(reverseURL), nil
+}
+
+// buildHost method composes the domain host for absolute URL, wildcard
+// subdomain value is taken from argument `SubdomainArg` and host param values
+// are taken from arguments by param name.
+func (d *Domain) buildHost(routeName string, args map[string]interface{}) (string, error) {
+	if !isHostPattern(d.Host) {
+		return d.Host, nil
+	}
+
+	labels := strings.Split(d.Host, ".")
+	for i, label := range labels {
+		switch {
+		case i == 0 && label == "*":
+			value, err := hostArgValue(routeName, SubdomainArg, args, fmt.Sprintf("domain host '%s' is wildcard", d.Host))
+			if err != nil {
+				return "", err
+			}
+			if !isValidSubdomain(value) {
+				return "", &RouteURLError{Route: routeName, Param: SubdomainArg, Err: ErrConstraintViolation,
+					Reason: fmt.Sprintf("value '%s' is not a valid subdomain", value)}
+			}
+			labels[i] = value
+		case len(label) > 1 && label[0] == paramByte:
+			name := label[1:]
+			value, err := hostArgValue(routeName, name, args, fmt.Sprintf("domain host '%s' has host param", d.Host))
+			if err != nil {
+				return "", err
+			}
+			if strings.IndexByte(value, dotByte) >= 0 || !isValidSubdomain(value) {
+				return "", &RouteURLError{Route: routeName, Param: name, Err: ErrConstraintViolation,
+					Reason: fmt.Sprintf("value '%s' is not a valid host label", value)}
+			}
+			labels[i] = value
+		case strings.IndexByte(label, wildByte) >= 0:
+			return "", &RouteURLError{Route: routeName, Err: ErrMissingParam,
+				Reason: fmt.Sprintf("domain host '%s' is a pattern, supply the actual host", d.Host)}
 		}
+	}
+	return strings.Join(labels, "."), nil
+}
 
-		reverseURL = fmt.Sprintf("%s?%s", reverseURL, urlValues.Encode())
+// routeArgs method returns the given arguments without the domain host
+// arguments, i.e. `SubdomainArg` and host params.
+func (d *Domain) routeArgs(args map[string]interface{}) map[string]interface{} {
+	names := []string{SubdomainArg}
+	for _, hp := range d.hostParams {
+		names = append(names, hp.name)
 	}
 
-	return reverseURL
+	found := false
+	for _, name := range names {
+		if _, found = args[name]; found {
+			break
+		}
+	}
+	if !found {
+		return args
+	}
+
+	routeArgs := make(map[string]interface{}, len(args))
+	for k, v := range args {
+		if !ess.IsSliceContainsString(names, k) {
+			routeArgs[k] = v
+		}
+	}
+	return routeArgs
 }
 
-// RouteURL method composes route reverse URL for given route and
-// arguments based on index order. If error occurs then method logs it
-// and returns empty string.
-func (d *Domain) RouteURL(routeName string, args ...interface{}) string {
-	route, found := d.routes[routeName]
+func hostArgValue(routeName, name string, args map[string]interface{}, reason string) (string, error) {
+	arg, found := args[name]
 	if !found {
-		log.Errorf("route name '%v' not found", routeName)
-		return ""
+		return "", &RouteURLError{Route: routeName, Param: name, Err: ErrMissingParam, Reason: reason}
 	}
 
-	argsLen := len(args)
-	pathParamCnt := countParams(route.Path)
-	if pathParamCnt == 0 && argsLen == 0 { // static URLs or no path params
-		return route.Path
+	value, err := formatPathValue(arg)
+	if err != nil {
+		return "", &RouteURLError{Route: routeName, Param: name, Err: ErrConstraintViolation, Reason: err.Error()}
 	}
+	return value, nil
+}
 
-	// too many arguments
-	if argsLen > int(pathParamCnt) {
-		log.Errorf("too many arguments, path: '%v' params count: %v, suppiled values count: %v",
-			route.Path, pathParamCnt, argsLen)
-		return ""
+// checkDuplicateNames method checks the given routes for duplicate route
+// names within the given routes and the routes already added to the domain.
+// All the duplicate names are reported together.
+func (d *Domain) checkDuplicateNames(routes []*Route) error {
+	named := make(map[string][]*Route, len(d.namedRoutes)+len(routes))
+	for name, r := range d.namedRoutes {
+		named[name] = append([]*Route{}, r...)
 	}
 
-	// not enough arguments
-	if argsLen < int(pathParamCnt) {
-		log.Errorf("not enough arguments, path: '%v' params count: %v, suppiled values count: %v",
-			route.Path, pathParamCnt, argsLen)
-		return ""
+	duplicates := make(map[string]string)
+	for _, route := range routes {
+		for _, r := range named[route.Name] {
+			if _, found := duplicates[route.Name]; !found &&
+				(r.Method == route.Method || !r.isSameDefinition(route)) {
+				duplicates[route.Name] = fmt.Sprintf("'%s' defined as [%s] and [%s]",
+					route.Name, r.definition(), route.definition())
+			}
+		}
+		named[route.Name] = append(named[route.Name], route)
 	}
 
-	var values []string
-	for _, v := range args {
-		values = append(values, fmt.Sprintf("%v", v))
+	if len(duplicates) == 0 {
+		return nil
 	}
 
-	// compose URL with values
-	reverseURL := "/"
-	idx := 0
-	for _, segment := range strings.Split(route.Path, "/") {
-		if len(segment) == 0 {
-			continue
+	names := make([]string, 0, len(duplicates))
+	for name := range duplicates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var msgs []string
+	for _, name := range names {
+		msgs = append(msgs, duplicates[name])
+	}
+	return fmt.Errorf("router: domain '%s' has duplicate route names: %s", d.Name, strings.Join(msgs, "; "))
+}
+
+// checkRoute method validates the route before it is inserted into routing
+// tree, given routes are yet to be added along with the route.
+func (d *Domain) checkRoute(route *Route, pending []*Route) error {
+	if ess.IsStrEmpty(route.Method) {
+		return errors.New("router: method value is empty")
+	}
+
+	if err := route.compileConstraints(); err != nil {
+		return err
+	}
+
+	if err := route.validateDefaults(); err != nil {
+		return err
+	}
+
+	if err := d.checkHostParams(route); err != nil {
+		return err
+	}
+
+	// route name is unique per domain, except the same route configured with
+	// multiple HTTP methods
+	named := d.namedRoutes[route.Name]
+	named = named[:len(named):len(named)] // append must not modify named routes
+	for _, r := range pending {
+		if r.Name == route.Name {
+			named = append(named, r)
+		}
+	}
+	for _, r := range named {
+		if r.Method == route.Method || !r.isSameDefinition(route) {
+			return fmt.Errorf("router: duplicate route name '%s', defined as [%s] and [%s]",
+				route.Name, r.definition(), route.definition())
 		}
+	}
 
-		if segment[0] == paramByte || segment[0] == wildByte {
-			reverseURL = path.Join(reverseURL, values[idx])
-			idx++
-			continue
+	return nil
+}
+
+func (d *Domain) addNamedRoute(route *Route) {
+	if _, found := d.routes[route.Name]; !found {
+		d.routes[route.Name] = route
+	}
+	if d.namedRoutes == nil {
+		d.namedRoutes = make(map[string][]*Route)
+	}
+	d.namedRoutes[route.Name] = append(d.namedRoutes[route.Name], route)
+}
+
+// requestPath method returns the request path to route on and lookup flags.
+// It is escaped path if domain `use_raw_path` is enabled.
+func (d *Domain) requestPath(req *http.Request) (string, lookupFlag) {
+	if d.UseRawPath {
+		return req.URL.EscapedPath(), escapedPath
+	}
+	return req.URL.Path, 0
+}
+
+// lookupTree method returns the route tree for the request method otherwise
+// nil. It takes care of HTTP method override and CORS preflight request.
+func (d *Domain) lookupTree(req *http.Request) *node {
+	// HTTP method override support
+	overrideMethod := req.Header.Get(ahttp.HeaderXHTTPMethodOverride)
+	if len(overrideMethod) > 0 && req.Method == ahttp.MethodPost {
+		req.Method = overrideMethod
+	}
+
+	// get route tree for request method
+	if tree, found := d.trees[req.Method]; found {
+		return tree
+	}
+
+	// get route tree for CORS access control method
+	if req.Method == ahttp.MethodOptions && d.CORSEnabled {
+		return d.trees[req.Header.Get(ahttp.HeaderAccessControlRequestMethod)]
+	}
+
+	return nil
+}
+
+// addHostParams method adds the domain host param values of given request
+// host into path params.
+func (d *Domain) addHostParams(host string, pathParams ahttp.PathParams) ahttp.PathParams {
+	if len(d.hostParams) == 0 {
+		return pathParams
+	}
+
+	hostname, _ := normalizeHost(host)
+	for _, hp := range d.hostParams {
+		if value, found := hostLabel(hostname, hp.index); found {
+			if pathParams == nil {
+				pathParams = make(ahttp.PathParams, len(d.hostParams))
+			}
+			pathParams[hp.name] = value
 		}
+	}
+	return pathParams
+}
 
-		reverseURL = path.Join(reverseURL, segment)
+// checkHostParams method checks the route path params against the domain host
+// params, both are returned in the same path params so name must be unique.
+func (d *Domain) checkHostParams(route *Route) error {
+	if len(d.hostParams) == 0 || countParams(route.Path) == 0 {
+		return nil
 	}
 
-	return reverseURL
+	tokens, err := parsePathTokens(route.Path)
+	if err != nil {
+		return nil // reported by tree insert
+	}
+	for _, t := range tokens {
+		if t.nType == static {
+			continue
+		}
+		name := strings.TrimLeft(t.path, "/:*")
+		for _, hp := range d.hostParams {
+			if hp.name == name {
+				return fmt.Errorf("router: route '%s' path param '%s' conflicts with domain host param", route.Name, name)
+			}
+		}
+	}
+	return nil
 }
 
-//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
-// Domain unexpoted methods
-//___________________________________
+// CanonicalHost method returns the domain host with port, it is used to
+// redirect the domain aliases to canonical host.
+func (d *Domain) CanonicalHost() string {
+	if len(d.Port) == 0 {
+		return d.Host
+	}
+	return d.Host + ":" + d.Port
+}
 
 func (d *Domain) inferKey() {
 	if len(d.Port) == 0 {
@@ -275,3 +790,34 @@ func (d *Domain) isAuthConfigured(secMgr *security.Manager) ([]string, bool) {
 
 	return names, len(names) == 0
 }
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// RouteURLError
+//___________________________________
+
+// RouteURLError is returned by reverse routing methods, it holds the route
+// name, param name and the cause. `Err` is one of the errors `ErrRouteNotFound`,
+// `ErrMissingParam`, `ErrTooManyParams` and `ErrConstraintViolation`.
+type RouteURLError struct {
+	Route  string
+	Param  string
+	Reason string
+	Err    error
+}
+
+// Error method is to implement error interface.
+func (e *RouteURLError) Error() string {
+	msg := fmt.Sprintf("%s, route '%s'", e.Err, e.Route)
+	if len(e.Param) > 0 {
+		msg += fmt.Sprintf(" param '%s'", e.Param)
+	}
+	if len(e.Reason) > 0 {
+		msg += ": " + e.Reason
+	}
+	return msg
+}
+
+// Unwrap method returns the cause of reverse routing error.
+func (e *RouteURLError) Unwrap() error {
+	return e.Err
+}
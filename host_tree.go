@@ -0,0 +1,507 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/router source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package router
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"aahframework.org/ahttp.v0"
+)
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Host tree
+//______________________________________________________________________________
+
+// hostTree is a suffix tree of domain host labels, labels are stored from
+// right to left. For e.g.: `api.sample.com` is stored as `com` -> `sample`
+// -> `api`. Supported host label patterns are -
+//
+//	sample.com       exact label
+//	api-*.sample.com label prefix, matches `api-eu.sample.com`
+//	:tenant.app.com  host param, matches one label and captures it
+//	api.*.sample.com wildcard, matches exactly one label
+//	*.sample.com     leftmost wildcard, matches one or more labels
+//
+// On lookup, labels are matched in the order of most to least specific, i.e.
+// exact, label prefix (longest first), host param, wildcard and leftmost
+// wildcard. Matching is done from right to left so the specific label on the
+// right wins.
+//
+// Exact hosts are kept in a map too, so exact host and alias are found with
+// one map lookup and labels are matched only if tree has host patterns.
+type hostTree struct {
+	root     hostNode
+	exact    map[string]*hostNode
+	patterns bool
+}
+
+type hostNodeType uint8
+
+const (
+	hostLabelStatic hostNodeType = iota
+	hostLabelPrefix
+	hostLabelParam
+	hostLabelWildcard
+	hostLabelCatchAll
+)
+
+// hostNode is a host tree node, `label` is the exact label, label prefix or
+// host param name based on node type.
+type hostNode struct {
+	nType    hostNodeType
+	label    string
+	statics  map[string]*hostNode
+	prefixes []*hostNode
+	param    *hostNode
+	wildcard *hostNode
+	catchAll *hostNode
+	entries  []*hostEntry
+}
+
+// hostEntry is the domain registered for the host, `alias` is true if the
+// host is domain alias.
+type hostEntry struct {
+	domain *Domain
+	alias  bool
+}
+
+// add method adds the given domain into host tree by domain host pattern.
+func (t *hostTree) add(d *Domain) error {
+	return t.addHost(d.Host, d, false)
+}
+
+// addAlias method adds the given alias host of domain into host tree.
+func (t *hostTree) addAlias(alias string, d *Domain) error {
+	return t.addHost(alias, d, true)
+}
+
+// lookup method returns the host entry for given normalized hostname and
+// port, also the captured host params. If `anyPort` is true and domain not
+// found for given port then first domain of the matched host is returned.
+func (t *hostTree) lookup(hostname, port string, anyPort bool) (*hostEntry, ahttp.PathParams) {
+	if n, found := t.exact[hostname]; found {
+		if e := n.entry(port, anyPort); e != nil {
+			return e, nil
+		}
+	}
+	if !t.patterns {
+		return nil, nil
+	}
+
+	labels := strings.Split(hostname, ".")
+	return t.root.match(labels, len(labels)-1, port, anyPort)
+}
+
+func (t *hostTree) addHost(pattern string, d *Domain, alias bool) error {
+	host := strings.TrimSuffix(strings.TrimSpace(pattern), ".")
+	labels := []string{host}
+	if ip, ok := ipv6Host(host); ok {
+		labels[0] = ip
+	} else {
+		labels = strings.Split(host, ".")
+	}
+
+	n, static := &t.root, true
+	for i := len(labels) - 1; i >= 0; i-- {
+		nType, label, err := parseHostLabel(labels[i], i == 0)
+		if err != nil {
+			return fmt.Errorf("value '%s' %s", pattern, err)
+		}
+		if n, err = n.child(nType, label); err != nil {
+			return fmt.Errorf("value '%s' %s", pattern, err)
+		}
+		labels[i], static = label, static && nType == hostLabelStatic
+	}
+
+	for _, e := range n.entries {
+		if e.domain.Port == d.Port || (e.domain.AnyPort && d.AnyPort) {
+			return fmt.Errorf("value '%s' conflicts with domain '%s'", pattern, e.domain.Name)
+		}
+	}
+	n.entries = append(n.entries, &hostEntry{domain: d, alias: alias})
+
+	if !static {
+		t.patterns = true
+	} else if len(n.entries) == 1 {
+		if t.exact == nil {
+			t.exact = make(map[string]*hostNode)
+		}
+		t.exact[strings.Join(labels, ".")] = n
+	}
+	return nil
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Host node unexported methods
+//______________________________________________________________________________
+
+// child method returns the child node for given type and label, it creates
+// the node if not exists.
+func (n *hostNode) child(nType hostNodeType, label string) (*hostNode, error) {
+	switch nType {
+	case hostLabelPrefix:
+		for _, c := range n.prefixes {
+			if c.label == label {
+				return c, nil
+			}
+		}
+		c := &hostNode{nType: nType, label: label}
+		n.prefixes = append(n.prefixes, c)
+		sort.SliceStable(n.prefixes, func(i, j int) bool {
+			return len(n.prefixes[i].label) > len(n.prefixes[j].label)
+		})
+		return c, nil
+	case hostLabelParam:
+		if n.param == nil {
+			n.param = &hostNode{nType: nType, label: label}
+		} else if n.param.label != label {
+			return nil, fmt.Errorf("host param ':%s' conflicts with existing host param ':%s'", label, n.param.label)
+		}
+		return n.param, nil
+	case hostLabelWildcard:
+		if n.wildcard == nil {
+			n.wildcard = &hostNode{nType: nType}
+		}
+		return n.wildcard, nil
+	case hostLabelCatchAll:
+		if n.catchAll == nil {
+			n.catchAll = &hostNode{nType: nType}
+		}
+		return n.catchAll, nil
+	}
+
+	if c, found := n.statics[label]; found {
+		return c, nil
+	}
+	if n.statics == nil {
+		n.statics = make(map[string]*hostNode)
+	}
+	c := &hostNode{nType: nType, label: label}
+	n.statics[label] = c
+	return c, nil
+}
+
+// match method matches the labels from index `i` to 0 against the node
+// children, it backtracks to the next specific child if the child does not
+// match the remaining labels.
+func (n *hostNode) match(labels []string, i int, port string, anyPort bool) (*hostEntry, ahttp.PathParams) {
+	if i < 0 {
+		return n.entry(port, anyPort), nil
+	}
+
+	label := labels[i]
+	if len(label) == 0 {
+		return nil, nil
+	}
+
+	if c, found := n.statics[label]; found {
+		if e, params := c.match(labels, i-1, port, anyPort); e != nil {
+			return e, params
+		}
+	}
+
+	for _, c := range n.prefixes {
+		if len(label) > len(c.label) && strings.HasPrefix(label, c.label) {
+			if e, params := c.match(labels, i-1, port, anyPort); e != nil {
+				return e, params
+			}
+		}
+	}
+
+	if n.param != nil {
+		if e, params := n.param.match(labels, i-1, port, anyPort); e != nil {
+			if params == nil {
+				params = make(ahttp.PathParams)
+			}
+			params[n.param.label] = label
+			return e, params
+		}
+	}
+
+	if n.wildcard != nil {
+		if e, params := n.wildcard.match(labels, i-1, port, anyPort); e != nil {
+			return e, params
+		}
+	}
+
+	if n.catchAll != nil {
+		for _, l := range labels[:i] {
+			if len(l) == 0 {
+				return nil, nil
+			}
+		}
+		if e := n.catchAll.entry(port, anyPort); e != nil {
+			return e, ahttp.PathParams{SubdomainArg: strings.Join(labels[:i+1], ".")}
+		}
+	}
+
+	return nil, nil
+}
+
+// entry method returns the host entry for given port, domain with exact port
+// is preferred over domain with `any_port`.
+func (n *hostNode) entry(port string, anyPort bool) *hostEntry {
+	for _, e := range n.entries {
+		if e.domain.Port == port {
+			return e
+		}
+	}
+	for _, e := range n.entries {
+		if e.domain.AnyPort {
+			return e
+		}
+	}
+	if anyPort && len(n.entries) > 0 {
+		return n.entries[0]
+	}
+	return nil
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Host index
+//______________________________________________________________________________
+
+// hostIndex is the domain index of router, it is built on `Load` and updated
+// on adding the domain or alias. Domains are found by key and host with one
+// map lookup, request host is matched in the host tree.
+type hostIndex struct {
+	keys  map[string]*Domain
+	names map[string]*Domain
+	tree  hostTree
+	def   *Domain
+}
+
+func newHostIndex() *hostIndex {
+	return &hostIndex{
+		keys:  make(map[string]*Domain),
+		names: make(map[string]*Domain),
+	}
+}
+
+// add method adds the given domain into index, domain host is validated
+// against the existing domain hosts.
+func (x *hostIndex) add(d *Domain) error {
+	if err := x.tree.add(d); err != nil {
+		return err
+	}
+
+	if _, found := x.keys[d.Key]; !found {
+		x.keys[d.Key] = d
+	}
+	if name := strings.ToLower(d.Host); x.names[name] == nil {
+		x.names[name] = d
+	}
+	if x.def == nil && d.IsDefault {
+		x.def = d
+	}
+	return nil
+}
+
+// addAlias method adds the given alias host of domain into index.
+func (x *hostIndex) addAlias(alias string, d *Domain) error {
+	return x.tree.addAlias(alias, d)
+}
+
+// lookup method returns the host entry for given normalized hostname and
+// port, also the captured host params same as `hostTree.lookup`.
+func (x *hostIndex) lookup(hostname, port string, anyPort bool) (*hostEntry, ahttp.PathParams) {
+	return x.tree.lookup(hostname, port, anyPort)
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Host param
+//______________________________________________________________________________
+
+// hostParam is the domain host param name and its label index from right,
+// for e.g.: `:tenant.app.com` has param `tenant` at index 2.
+type hostParam struct {
+	name  string
+	index int
+}
+
+// parseHostParams method returns the host params of given domain host pattern.
+func parseHostParams(host string) []hostParam {
+	host = strings.TrimSuffix(strings.TrimSpace(host), ".")
+	if _, ok := ipv6Host(host); ok {
+		return nil
+	}
+
+	labels := strings.Split(host, ".")
+	var params []hostParam
+	for i, label := range labels {
+		if len(label) > 1 && label[0] == paramByte {
+			params = append(params, hostParam{name: label[1:], index: len(labels) - 1 - i})
+		}
+	}
+	return params
+}
+
+// hostLabel method returns the label of given hostname at index from right
+// without allocation, for e.g.: `tenant1.app.com` label at index 2 is
+// `tenant1`.
+func hostLabel(hostname string, index int) (string, bool) {
+	end := len(hostname)
+	for i := len(hostname) - 1; i >= -1; i-- {
+		if i >= 0 && hostname[i] != dotByte {
+			continue
+		}
+		if index == 0 {
+			return hostname[i+1 : end], i+1 < end
+		}
+		index--
+		end = i
+	}
+	return "", false
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Unexported methods
+//______________________________________________________________________________
+
+// parseHostLabel method parses the host pattern label into node type and
+// label value.
+func parseHostLabel(label string, leftmost bool) (hostNodeType, string, error) {
+	switch {
+	case len(label) == 0:
+		return hostLabelStatic, "", fmt.Errorf("has empty label")
+	case label == "*":
+		if leftmost {
+			return hostLabelCatchAll, "", nil
+		}
+		return hostLabelWildcard, "", nil
+	case label[0] == paramByte:
+		name := label[1:]
+		if len(name) == 0 || !isHostParamName(name) {
+			return hostLabelParam, "", fmt.Errorf("has invalid host param '%s'", label)
+		}
+		return hostLabelParam, name, nil
+	}
+
+	if idx := strings.IndexByte(label, wildByte); idx >= 0 {
+		if idx != len(label)-1 {
+			return hostLabelPrefix, "", fmt.Errorf("has invalid label '%s', wildcard is supported only at the end of label", label)
+		}
+		return hostLabelPrefix, idnaASCII(strings.ToLower(label[:idx])), nil
+	}
+
+	return hostLabelStatic, idnaASCII(strings.ToLower(label)), nil
+}
+
+func isHostParamName(name string) bool {
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c != '_' && !(c >= 'a' && c <= 'z') && !(c >= 'A' && c <= 'Z') && !(c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// isHostPattern method returns true if given host has any label pattern.
+// IPv6 address is not a pattern.
+func isHostPattern(host string) bool {
+	if _, ok := ipv6Host(strings.TrimSpace(host)); ok {
+		return false
+	}
+	for _, label := range strings.Split(host, ".") {
+		if strings.IndexByte(label, wildByte) >= 0 || (len(label) > 0 && label[0] == paramByte) {
+			return true
+		}
+	}
+	return false
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Host normalization
+//______________________________________________________________________________
+
+// HostMapper interface is used to convert the internationalized domain host
+// or host label into IDNA ASCII form, for e.g.: `bücher` becomes
+// `xn--bcher-kva`. Profile `idna.Lookup` of package `golang.org/x/net/idna`
+// satisfies it.
+type HostMapper interface {
+	ToASCII(s string) (string, error)
+}
+
+// hostMapper holds the mapper registered via `SetHostMapper`.
+var hostMapper HostMapper
+
+// SetHostMapper method sets the IDNA mapper for internationalized domain
+// hosts. Domain hosts are mapped once while building the domain index and
+// request host is mapped only if it is not ASCII. Without mapper, host is
+// matched as lowercase value.
+//
+// Note: It is not concurrency-safe, set it before the routes are loaded.
+func SetHostMapper(m HostMapper) {
+	hostMapper = m
+}
+
+// normalizeHost method normalizes the given request host into hostname and
+// port. Default ports `80` and `443` are removed, refer to `normalizeHostname`
+// for hostname.
+func normalizeHost(host string) (string, string) {
+	hostname, port := splitHostPort(strings.TrimSpace(host))
+	if port == "80" || port == "443" {
+		port = ""
+	}
+	return normalizeHostname(hostname), port
+}
+
+// normalizeHostname method returns the lowercase hostname without trailing
+// dot, IPv6 address is returned in canonical form within brackets and
+// internationalized hostname is converted into IDNA ASCII form using the
+// host mapper, for e.g.: `Bücher.example.` becomes `xn--bcher-kva.example`.
+func normalizeHostname(hostname string) string {
+	hostname = strings.TrimSuffix(hostname, ".")
+	if ip, ok := ipv6Host(hostname); ok {
+		return ip
+	}
+
+	return idnaASCII(strings.ToLower(hostname))
+}
+
+// ipv6Host method returns the canonical IPv6 address within brackets if given
+// host is IPv6 address with or without brackets, for e.g.: `::FFFF:1.2.3.4`
+// becomes `[::ffff:1.2.3.4]`.
+func ipv6Host(host string) (string, bool) {
+	if strings.IndexByte(host, ':') == -1 {
+		return "", false
+	}
+	ip := net.ParseIP(strings.TrimSuffix(strings.TrimPrefix(host, "["), "]"))
+	if ip == nil {
+		return "", false
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		// IPv4-mapped address, `net.IP.String` returns it in IPv4 form
+		return "[::ffff:" + ip4.String() + "]", true
+	}
+	return "[" + ip.String() + "]", true
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// idnaASCII method returns the ASCII form of given host or label using the
+// host mapper, refer to `SetHostMapper`. ASCII value, value which fails IDNA
+// validation or value without host mapper is returned as-is.
+func idnaASCII(s string) string {
+	if hostMapper == nil || isASCII(s) {
+		return s
+	}
+	if a, err := hostMapper.ToASCII(s); err == nil {
+		return a
+	}
+	return s
+}
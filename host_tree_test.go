@@ -0,0 +1,269 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/router source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package router
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"aahframework.org/ahttp.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestHostTreeLookup(t *testing.T) {
+	tree := &hostTree{}
+	for _, host := range []string{"sample.com", "www.sample.com", "*.sample.com", "api-*.sample.com",
+		"api-eu-*.sample.com", "api.*.sample.com", "*.eu.sample.com", ":tenant.app.com",
+		"admin.app.com", ":region.:tenant.corp.com", "[::1]"} {
+		assert.Nil(t, tree.add(&Domain{Name: host, Host: host, Port: "8080"}))
+	}
+	assert.Nil(t, tree.add(&Domain{Name: "sample.com:9000", Host: "sample.com", Port: "9000"}))
+
+	for _, tc := range []struct {
+		host, port string
+		anyPort    bool
+		domain     string
+		params     ahttp.PathParams
+	}{
+		{host: "sample.com", port: "8080", domain: "sample.com"},
+		{host: "sample.com", port: "9000", domain: "sample.com:9000"},
+		{host: "sample.com", port: "9090"},
+		{host: "sample.com", anyPort: true, domain: "sample.com"},
+		{host: "www.sample.com", port: "8080", domain: "www.sample.com"},
+		{host: "tenant1.sample.com", port: "8080", domain: "*.sample.com",
+			params: ahttp.PathParams{SubdomainArg: "tenant1"}},
+		{host: "a.b.sample.com", port: "8080", domain: "*.sample.com",
+			params: ahttp.PathParams{SubdomainArg: "a.b"}},
+		{host: "api-us.sample.com", port: "8080", domain: "api-*.sample.com"},
+		{host: "api-eu-west.sample.com", port: "8080", domain: "api-eu-*.sample.com"},
+		{host: "api-.sample.com", port: "8080", domain: "*.sample.com",
+			params: ahttp.PathParams{SubdomainArg: "api-"}},
+		{host: "api.us.sample.com", port: "8080", domain: "api.*.sample.com"},
+		{host: "api.eu.sample.com", port: "8080", domain: "*.eu.sample.com",
+			params: ahttp.PathParams{SubdomainArg: "api"}},
+		{host: "web.us.sample.com", port: "8080", domain: "*.sample.com",
+			params: ahttp.PathParams{SubdomainArg: "web.us"}},
+		{host: "tenant1.app.com", port: "8080", domain: ":tenant.app.com",
+			params: ahttp.PathParams{"tenant": "tenant1"}},
+		{host: "admin.app.com", port: "8080", domain: "admin.app.com"},
+		{host: "a.b.app.com", port: "8080"},
+		{host: "eu.acme.corp.com", port: "8080", domain: ":region.:tenant.corp.com",
+			params: ahttp.PathParams{"region": "eu", "tenant": "acme"}},
+		{host: "[::1]", port: "8080", domain: "[::1]"},
+		{host: ".sample.com", port: "8080"},
+		{host: "a..sample.com", port: "8080"},
+		{host: "example.com", port: "8080"},
+	} {
+		e, params := tree.lookup(tc.host, tc.port, tc.anyPort)
+		if len(tc.domain) == 0 {
+			assert.Nilf(t, e, "host '%s'", tc.host)
+			continue
+		}
+		assert.NotNilf(t, e, "host '%s'", tc.host)
+		assert.Equal(t, tc.domain, e.domain.Name)
+		assert.Equal(t, tc.params, params)
+	}
+}
+
+func TestHostTreeErrors(t *testing.T) {
+	tree := &hostTree{}
+	assert.Nil(t, tree.add(&Domain{Name: "tenant", Host: ":tenant.app.com"}))
+	assert.Nil(t, tree.add(&Domain{Name: "sample", Host: "Sample.com"}))
+
+	for host, msg := range map[string]string{
+		"sample.com":       "value 'sample.com' conflicts with domain 'sample'",
+		":org.app.com":     "value ':org.app.com' host param ':org' conflicts with existing host param ':tenant'",
+		"sample..com":      "value 'sample..com' has empty label",
+		"a*b.sample.com":   "value 'a*b.sample.com' has invalid label 'a*b', wildcard is supported only at the end of label",
+		":.sample.com":     "value ':.sample.com' has invalid host param ':'",
+		":ten-ant.app.com": "value ':ten-ant.app.com' has invalid host param ':ten-ant'",
+	} {
+		err := tree.add(&Domain{Host: host})
+		assert.NotNilf(t, err, "host '%s'", host)
+		assert.Equal(t, msg, err.Error())
+	}
+
+	assert.True(t, isHostPattern("*.sample.com"))
+	assert.True(t, isHostPattern("api-*.sample.com"))
+	assert.True(t, isHostPattern(":tenant.app.com"))
+	assert.False(t, isHostPattern("sample.com"))
+	assert.False(t, isHostPattern("[::1]"))
+}
+
+func TestHostParams(t *testing.T) {
+	assert.Nil(t, parseHostParams("sample.com"))
+	assert.Equal(t, []hostParam{{name: "region", index: 3}, {name: "tenant", index: 2}},
+		parseHostParams(":region.:tenant.corp.com"))
+	assert.Nil(t, parseHostParams("::1"))
+	assert.False(t, isHostPattern("[::1]"))
+	assert.True(t, isHostPattern(":tenant.corp.com"))
+
+	for index, expected := range map[int]string{0: "com", 1: "corp", 2: "acme", 3: "eu", 4: ""} {
+		label, found := hostLabel("eu.acme.corp.com", index)
+		assert.Equal(t, expected, label)
+		assert.Equal(t, len(expected) > 0, found)
+	}
+	_, found := hostLabel("a..com", 1)
+	assert.False(t, found)
+}
+
+func TestHostNormalization(t *testing.T) {
+	// without host mapper, internationalized host is lowercased only
+	hostname, _ := normalizeHost("Bücher.example")
+	assert.Equal(t, "bücher.example", hostname)
+
+	SetHostMapper(testHostMapper)
+	defer SetHostMapper(nil)
+
+	for host, expected := range map[string][2]string{
+		"Sample.COM":              {"sample.com", ""},
+		"sample.com.:443":         {"sample.com", ""},
+		"sample.com:80":           {"sample.com", ""},
+		"sample.com:8443":         {"sample.com", "8443"},
+		" sample.com ":            {"sample.com", ""},
+		"[::1]:8080":              {"[::1]", "8080"},
+		"[0:0:0:0:0:0:0:1]":       {"[::1]", ""},
+		"[2001:DB8::1]:443":       {"[2001:db8::1]", ""},
+		"::1":                     {"[::1]", ""},
+		"2001:db8::8080":          {"[2001:db8::8080]", ""},
+		"::FFFF:1.2.3.4":          {"[::ffff:1.2.3.4]", ""},
+		"[::ffff:1.2.3.4]:8080":   {"[::ffff:1.2.3.4]", "8080"},
+		"10.0.0.1:8080":           {"10.0.0.1", "8080"},
+		"Bücher.example:8080":     {"xn--bcher-kva.example", "8080"},
+		"xn--bcher-kva.example":   {"xn--bcher-kva.example", ""},
+		"münchen.de.":             {"xn--mnchen-3ya.de", ""},
+		"中国.example":              {"xn--fiqs8s.example", ""},
+		"tenant1.sample.com:9000": {"tenant1.sample.com", "9000"},
+	} {
+		hostname, port := normalizeHost(host)
+		assert.Equal(t, expected[0], hostname)
+		assert.Equal(t, expected[1], port)
+	}
+
+	for label, expected := range map[string]string{
+		"bücher":  "xn--bcher-kva",
+		"münchen": "xn--mnchen-3ya",
+		"中国":      "xn--fiqs8s",
+		"api-":    "api-",
+		"ä":       "ä",
+	} {
+		assert.Equal(t, expected, idnaASCII(label))
+	}
+}
+
+func TestHostTreeAnyPort(t *testing.T) {
+	SetHostMapper(testHostMapper)
+	defer SetHostMapper(nil)
+
+	tree := &hostTree{}
+	assert.Nil(t, tree.add(&Domain{Name: "any", Host: "sample.com", Port: "8080", AnyPort: true}))
+	assert.Nil(t, tree.add(&Domain{Name: "admin", Host: "sample.com", Port: "9000"}))
+	assert.Nil(t, tree.add(&Domain{Name: "ipv6", Host: "::1", Port: "8080"}))
+	assert.Nil(t, tree.add(&Domain{Name: "idn", Host: "Bücher.example.", Port: "8080"}))
+	assert.Equal(t, "value 'Sample.com' conflicts with domain 'any'",
+		tree.add(&Domain{Host: "Sample.com", Port: "7000", AnyPort: true}).Error())
+
+	for _, tc := range []struct{ host, domain string }{
+		{"sample.com:8080", "any"},
+		{"sample.com:8443", "any"},
+		{"sample.com", "any"},
+		{"sample.com:9000", "admin"},
+		{"[0::1]:8080", "ipv6"},
+		{"bücher.example.:8080", "idn"},
+		{"xn--bcher-kva.example:8080", "idn"},
+	} {
+		hostname, port := normalizeHost(tc.host)
+		e, _ := tree.lookup(hostname, port, false)
+		assert.NotNilf(t, e, "host '%s'", tc.host)
+		assert.Equal(t, tc.domain, e.domain.Name)
+	}
+}
+
+func TestHostIndex(t *testing.T) {
+	x := newHostIndex()
+	for _, d := range []*Domain{
+		{Name: "sample", Host: "sample.com", Port: "8080"},
+		{Name: "sample_any", Host: "Sample.com", Port: "9000", AnyPort: true},
+		{Name: "ipv6", Host: "::1", Port: "8080"},
+		{Name: "default", Host: "localhost", Port: "8080", IsDefault: true},
+	} {
+		d.inferKey()
+		assert.Nil(t, x.add(d))
+	}
+	sample := x.keys["sample.com:8080"]
+	assert.Nil(t, x.addAlias("WWW.sample.com.", sample))
+
+	// conflicts are not added
+	conflict := &Domain{Name: "conflict", Host: "www.sample.com", Port: "8080"}
+	conflict.inferKey()
+	assert.Equal(t, "value 'www.sample.com' conflicts with domain 'sample'", x.add(conflict).Error())
+	assert.Nil(t, x.keys["www.sample.com:8080"])
+
+	assert.False(t, x.tree.patterns)
+	assert.Equal(t, "default", x.def.Name)
+	assert.Equal(t, "sample", sample.Name)
+	assert.Equal(t, "sample", x.names["sample.com"].Name)
+	assert.Nil(t, x.keys["sample.com"])
+	assert.Equal(t, "sample_any", x.keys["sample.com:9000"].Name)
+	assert.Equal(t, 4, len(x.tree.exact))
+
+	for _, tc := range []struct{ host, domain string }{
+		{"sample.com:8080", "sample"},
+		{"sample.com:7000", "sample_any"},
+		{"www.sample.com:8080", "sample"},
+		{"[::1]:8080", "ipv6"},
+		{"localhost:8080", "default"},
+		{"a.sample.com:8080", ""},
+		{"localhost:9000", ""},
+	} {
+		hostname, port := normalizeHost(tc.host)
+		e, _ := x.lookup(hostname, port, false)
+		if len(tc.domain) == 0 {
+			assert.Nilf(t, e, "host '%s'", tc.host)
+			continue
+		}
+		assert.NotNilf(t, e, "host '%s'", tc.host)
+		assert.Equal(t, tc.domain, e.domain.Name)
+	}
+
+	// host patterns are matched in the tree
+	assert.Nil(t, x.add(&Domain{Name: "tenant", Host: ":tenant.sample.com", Port: "8080"}))
+	assert.True(t, x.tree.patterns)
+	e, params := x.lookup("a.sample.com", "8080", false)
+	assert.Equal(t, "tenant", e.domain.Name)
+	assert.Equal(t, "a", params.Get("tenant"))
+	e, params = x.lookup("www.sample.com", "8080", false)
+	assert.True(t, e.alias)
+	assert.Nil(t, params)
+}
+
+// testHostMapper maps the internationalized host labels used in the tests,
+// unknown label fails same as IDNA validation.
+var testHostMapper = hostMapperFunc(func(s string) (string, error) {
+	labels := strings.Split(s, ".")
+	for i, label := range labels {
+		if isASCII(label) {
+			continue
+		}
+		switch label {
+		case "bücher":
+			labels[i] = "xn--bcher-kva"
+		case "münchen":
+			labels[i] = "xn--mnchen-3ya"
+		case "中国":
+			labels[i] = "xn--fiqs8s"
+		default:
+			return "", errors.New("idna: invalid label " + label)
+		}
+	}
+	return strings.Join(labels, "."), nil
+})
+
+type hostMapperFunc func(s string) (string, error)
+
+func (f hostMapperFunc) ToASCII(s string) (string, error) {
+	return f(s)
+}
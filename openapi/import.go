@@ -0,0 +1,388 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/router source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"aahframework.org/router.v0"
+	"aahframework.org/vfs.v0"
+	"gopkg.in/yaml.v2"
+)
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Package methods
+//______________________________________________________________________________
+
+// LoadFile method reads the OpenAPI 3 document from given file, JSON or YAML.
+// File is read from VFS if given `fs` is not nil otherwise from disk.
+func LoadFile(fs *vfs.VFS, filename string) (*Document, error) {
+	b, err := vfs.ReadFile(fs, filename)
+	if err != nil {
+		return nil, fmt.Errorf("openapi: %s", err)
+	}
+	return Parse(b)
+}
+
+// Parse method parses the given OpenAPI 3 document, JSON or YAML.
+func Parse(b []byte) (*Document, error) {
+	b = bytes.TrimSpace(b)
+	if len(b) > 0 && b[0] != '{' {
+		var v interface{}
+		if err := yaml.Unmarshal(b, &v); err != nil {
+			return nil, fmt.Errorf("openapi: %s", err)
+		}
+
+		var err error
+		if b, err = json.Marshal(yamlToJSONValue(v)); err != nil {
+			return nil, fmt.Errorf("openapi: %s", err)
+		}
+	}
+
+	doc := &Document{}
+	if err := json.Unmarshal(b, doc); err != nil {
+		return nil, fmt.Errorf("openapi: %s", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("openapi: unsupported version '%s', supports OpenAPI 3", doc.OpenAPI)
+	}
+
+	doc.operations = make(map[string]*Operation)
+	for _, item := range doc.Paths {
+		for _, op := range item {
+			if len(op.OperationID) > 0 {
+				doc.operations[op.OperationID] = op
+			}
+		}
+	}
+	return doc, nil
+}
+
+// ImportFile method reads the OpenAPI 3 document from given file and
+// registers the routes into domain, refer to `Import`.
+func ImportFile(domain *router.Domain, fs *vfs.VFS, filename string) error {
+	doc, err := LoadFile(fs, filename)
+	if err != nil {
+		return err
+	}
+	return Import(domain, doc)
+}
+
+// Import method registers the routes of given OpenAPI document into domain.
+// Routes are added same as routes config, so route conflicts are reported
+// and values are inherited from domain.
+//
+// Route controller and action are taken from `operationId` in the format
+// `Controller.Action`, for e.g.: `User.Show` or from the extensions
+// `x-aah-controller` and `x-aah-action`. Route name is `x-aah-route-name`
+// otherwise `operationId`.
+//
+// Path param constraints are taken from `x-aah-constraint` otherwise derived
+// from param schema. Route auth is taken from `x-aah-auth` otherwise from
+// operation security, empty security becomes `anonymous`. Other supported
+// extensions are `x-aah-cors`, `x-aah-max-body-size`, `x-aah-anti-csrf-check`,
+// `x-aah-owner` and `x-aah-sunset`.
+func Import(domain *router.Domain, doc *Document) error {
+	if domain == nil {
+		return ErrDomainIsNil
+	}
+
+	paths := make([]string, 0, len(doc.Paths))
+	for p := range doc.Paths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	// routes are added once all the operations are converted, so domain is
+	// not left with partially imported document on error
+	var routes []*router.Route
+	for _, p := range paths {
+		for _, method := range httpMethods {
+			op, found := doc.Paths[p][method]
+			if !found {
+				continue
+			}
+			opRoutes, err := operationRoutes(domain, p, strings.ToUpper(method), op)
+			if err != nil {
+				return fmt.Errorf("openapi: operation '%s %s': %s", strings.ToUpper(method), p, err)
+			}
+			routes = append(routes, opRoutes...)
+		}
+	}
+
+	if err := domain.AddRoutes(routes); err != nil {
+		return fmt.Errorf("openapi: %s", err)
+	}
+	return nil
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Unexported methods
+//______________________________________________________________________________
+
+// operationRoutes method creates the routes of OpenAPI operation, routes are
+// not added into domain.
+func operationRoutes(domain *router.Domain, oasPath, method string, op *Operation) ([]*router.Route, error) {
+	target, action := op.Controller, op.Action
+	if len(target) == 0 || len(action) == 0 {
+		idx := strings.LastIndexByte(op.OperationID, '.')
+		if idx <= 0 || idx == len(op.OperationID)-1 {
+			return nil, fmt.Errorf("operationId '%s' is not in 'Controller.Action' format", op.OperationID)
+		}
+		if len(target) == 0 {
+			target = op.OperationID[:idx]
+		}
+		if len(action) == 0 {
+			action = op.OperationID[idx+1:]
+		}
+	}
+
+	name := op.RouteName
+	if len(name) == 0 {
+		name = op.OperationID
+	}
+
+	g := domain.Group("")
+	if auth := operationAuth(op); len(auth) > 0 {
+		g.Auth(auth)
+	}
+	if op.CORS != nil {
+		g.CORS(routeCORS(op.CORS))
+	}
+	if size, ok := op.MaxBodySize.(string); ok {
+		g.MaxBodySize(size)
+	}
+	if op.AntiCSRFCheck != nil {
+		g.AntiCSRFCheck(*op.AntiCSRFCheck)
+	}
+	if len(op.Tags) > 0 {
+		g.Tags(op.Tags...)
+	}
+	if len(op.Owner) > 0 {
+		g.Owner(op.Owner)
+	}
+	if op.Deprecated || len(op.Sunset) > 0 {
+		sunset, err := parseSunset(op.Sunset)
+		if err != nil {
+			return nil, err
+		}
+		g.Deprecated(sunset)
+	}
+
+	routePath, constraints := routePath(oasPath, op.Parameters)
+	routes, err := g.NewRoutes(name, method, routePath, target, action)
+	if err != nil {
+		return nil, err
+	}
+
+	description := op.Summary
+	if len(description) == 0 {
+		description = op.Description
+	}
+	for _, route := range routes {
+		route.Constraints = constraints
+		if size, ok := op.MaxBodySize.(float64); ok && route.MaxBodySize > 0 {
+			// max body size is applicable only for payload methods
+			route.MaxBodySize = int64(size)
+		}
+		route.Description = description
+	}
+
+	return routes, nil
+}
+
+// routeCORS method creates the route CORS from extension `x-aah-cors`.
+func routeCORS(c *CORS) *router.CORS {
+	cors := (&router.CORS{}).
+		AddOrigins(c.AllowOrigins).
+		AddAllowMethods(c.AllowMethods).
+		AddAllowHeaders(c.AllowHeaders).
+		AddExposeHeaders(c.ExposeHeaders).
+		SetAllowCredentials(c.AllowCredentials)
+	if len(c.MaxAge) > 0 {
+		maxAge := c.MaxAge
+		if _, err := strconv.Atoi(maxAge); err == nil {
+			// generated document has max age in seconds
+			maxAge += "s"
+		}
+		cors.SetMaxAge(maxAge)
+	}
+	return cors
+}
+
+// operationAuth method returns the auth scheme name of operation, it is
+// `x-aah-auth` otherwise first security requirement scheme.
+func operationAuth(op *Operation) string {
+	if len(op.Auth) > 0 || op.Security == nil {
+		return op.Auth
+	}
+	if len(*op.Security) == 0 {
+		return authAnonymous
+	}
+
+	var names []string
+	for name := range (*op.Security)[0] {
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return authAnonymous
+	}
+	sort.Strings(names)
+	return names[0]
+}
+
+// routePath method converts the OpenAPI path into aah route path and returns
+// it with path param constraints, for e.g.: `/users/{id}` becomes
+// `/users/:id` and constraint `id` => `int`.
+func routePath(oasPath string, params []*Parameter) (string, map[string]string) {
+	var constraints map[string]string
+	buf := make([]byte, 0, len(oasPath))
+	for i := 0; i < len(oasPath); i++ {
+		if oasPath[i] != '{' {
+			buf = append(buf, oasPath[i])
+			continue
+		}
+
+		end := strings.IndexByte(oasPath[i:], '}')
+		if end == -1 {
+			buf = append(buf, oasPath[i:]...)
+			break
+		}
+
+		name := oasPath[i+1 : i+end]
+		param := findPathParam(params, name)
+		if param != nil && param.CatchAll {
+			buf = append(buf, '*')
+		} else {
+			buf = append(buf, ':')
+		}
+		buf = append(buf, name...)
+
+		if param != nil {
+			constraint := param.Constraint
+			if len(constraint) == 0 {
+				constraint = schemaConstraint(param.Schema)
+			}
+			if len(constraint) > 0 {
+				if constraints == nil {
+					constraints = make(map[string]string)
+				}
+				constraints[name] = constraint
+			}
+		}
+		i += end
+	}
+	return string(buf), constraints
+}
+
+func findPathParam(params []*Parameter, name string) *Parameter {
+	for _, p := range params {
+		if p.In == "path" && p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// schemaConstraint method derives the aah route constraint from the param
+// schema, it is reverse of `constraintSchema`. Path param value is validated
+// as string, so numeric `minimum` and `maximum` are not derived except
+// non-negative integer becomes `uint`.
+func schemaConstraint(s *Schema) string {
+	if s == nil {
+		return ""
+	}
+
+	var rules []string
+	if s.Type == "integer" {
+		if s.Minimum != nil && *s.Minimum >= 0 {
+			rules = append(rules, "uint")
+		} else {
+			rules = append(rules, "int")
+		}
+	}
+	switch s.Format {
+	case "uuid", "date":
+		rules = append(rules, s.Format)
+	}
+	switch {
+	case s.MinLength != nil && s.MaxLength != nil && *s.MinLength == *s.MaxLength:
+		rules = append(rules, "len="+strconv.Itoa(*s.MinLength))
+	default:
+		if s.MinLength != nil {
+			rules = append(rules, "min="+strconv.Itoa(*s.MinLength))
+		}
+		if s.MaxLength != nil {
+			rules = append(rules, "max="+strconv.Itoa(*s.MaxLength))
+		}
+	}
+	if len(s.Enum) > 0 {
+		values := make([]string, 0, len(s.Enum))
+		for _, v := range s.Enum {
+			values = append(values, fmt.Sprint(v))
+		}
+		rules = append(rules, "oneof="+strings.Join(values, " "))
+	}
+	if len(s.Pattern) > 0 {
+		rules = append(rules, patternRule(s.Pattern))
+	}
+	return strings.Join(rules, ",")
+}
+
+// patternRule method returns the named constraint for the known patterns
+// otherwise regex constraint.
+func patternRule(pattern string) string {
+	switch pattern {
+	case "^[a-zA-Z]+$":
+		return "alpha"
+	case "^[a-zA-Z0-9]+$":
+		return "alphanum"
+	case "^[a-z0-9]+(?:-[a-z0-9]+)*$":
+		return "slug"
+	}
+	if strings.HasPrefix(pattern, "^(?:") && strings.HasSuffix(pattern, ")$") {
+		pattern = pattern[4 : len(pattern)-2]
+	}
+	return "regex(" + pattern + ")"
+}
+
+func parseSunset(v string) (time.Time, error) {
+	if len(v) == 0 {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse("2006-01-02", v); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return t, fmt.Errorf("x-aah-sunset value '%s' is invalid, it should be date '2006-01-02' or RFC3339 format", v)
+	}
+	return t, nil
+}
+
+// yamlToJSONValue method converts the YAML decoded value into JSON compatible
+// value, YAML map keys are converted into string.
+func yamlToJSONValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, v := range t {
+			m[fmt.Sprint(k)] = yamlToJSONValue(v)
+		}
+		return m
+	case []interface{}:
+		for i := range t {
+			t[i] = yamlToJSONValue(t[i])
+		}
+		return t
+	}
+	return v
+}
@@ -0,0 +1,207 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/router source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package openapi
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"aahframework.org/ahttp.v0"
+	"aahframework.org/router.v0"
+	"aahframework.org/test.v0/assert"
+	"aahframework.org/vfs.v0"
+)
+
+func TestOpenAPIImportFile(t *testing.T) {
+	fs := &vfs.VFS{}
+	assert.Nil(t, fs.AddMount("/app/spec", "testdata"))
+
+	d := (&router.Router{}).AddDomain("petstore.com", "8080")
+	d.DefaultAuth = "form_auth"
+	d.CORSEnabled = true
+	assert.Nil(t, ImportFile(d, fs, "/app/spec/petstore.yaml"))
+	assert.Equal(t, 5, len(d.Routes()))
+
+	route := d.LookupByName("Pet.List")
+	assert.Equal(t, "/pets", route.Path)
+	assert.Equal(t, "Pet", route.Target)
+	assert.Equal(t, "List", route.Action)
+	assert.Equal(t, "anonymous", route.Auth)
+	assert.Equal(t, "List all pets", route.Description)
+	assert.Equal(t, []string{"pets"}, route.Tags)
+
+	route = d.LookupByName("pet_create")
+	assert.Equal(t, ahttp.MethodPost, route.Method)
+	assert.Equal(t, "api_key", route.Auth)
+	assert.Equal(t, int64(2097152), route.MaxBodySize)
+	assert.False(t, route.IsAntiCSRFCheck)
+
+	// path level params
+	route = d.LookupByName("Pet.Show")
+	assert.Equal(t, "/pets/:petId", route.Path)
+	assert.Equal(t, "uint", route.Constraints["petId"])
+	assert.Equal(t, "form_auth", route.Auth)
+	assert.Equal(t, "Info for a specific pet", route.Description)
+	assert.Equal(t, "pets-team", route.Owner)
+	assert.True(t, route.Deprecated)
+	assert.Equal(t, time.Date(2019, 12, 31, 0, 0, 0, 0, time.UTC), route.Sunset)
+	assert.Equal(t, []string{"https://petstore.com"}, route.CORS.AllowOrigins)
+
+	route = d.LookupByName("Pet.Delete")
+	assert.Equal(t, ahttp.MethodDelete, route.Method)
+	assert.Equal(t, "jwt", route.Auth)
+
+	// catch-all and namespace controller
+	route = d.LookupByName("admin/Photo.Serve")
+	assert.Equal(t, "/pets/:petId/photos/*filepath", route.Path)
+	assert.Equal(t, "admin/Photo", route.Target)
+	assert.Equal(t, "int", route.Constraints["petId"])
+
+	route, pathParams, _ := d.Lookup(createHTTPRequest("/pets/10/photos/a/b.png"))
+	assert.Equal(t, "admin/Photo.Serve", route.Name)
+	assert.Equal(t, "/a/b.png", pathParams.Get("filepath"))
+
+	route, _, _ = d.Lookup(createHTTPRequest("/pets/-1"))
+	assert.Nil(t, route)
+
+	err := ImportFile(d, fs, "/app/spec/not-exists.yaml")
+	assert.NotNil(t, err)
+}
+
+func TestOpenAPIImportRoundTrip(t *testing.T) {
+	src := createDomain(t)
+	doc, err := Generate(src, nil)
+	assert.Nil(t, err)
+
+	for _, format := range []string{"json", "yaml"} {
+		var b []byte
+		if format == "json" {
+			b, err = doc.JSON()
+		} else {
+			b, err = doc.YAML()
+		}
+		assert.Nil(t, err)
+
+		parsed, err := Parse(b)
+		assert.Nil(t, err)
+		assert.Equal(t, "user_show", parsed.Operation("user_show").RouteName)
+
+		d := (&router.Router{}).AddDomain("api.sample.com", "8443")
+		d.DefaultAuth = "form_auth"
+		assert.Nil(t, Import(d, parsed))
+
+		for _, name := range []string{"user_show", "user_update", "file_serve", "image_show", "app_health"} {
+			for _, expected := range src.RoutesByName(name) {
+				route := d.LookupByNameMethod(name, expected.Method)
+				assert.NotNilf(t, route, "%s route '%s'", format, name)
+				assert.Equal(t, expected.Path, route.Path)
+				assert.Equal(t, expected.Target, route.Target)
+				assert.Equal(t, expected.Action, route.Action)
+				assert.Equal(t, expected.Auth, route.Auth)
+				assert.Equal(t, expected.MaxBodySize, route.MaxBodySize)
+				assert.Equal(t, expected.Tags, route.Tags)
+				assert.Equal(t, expected.Deprecated, route.Deprecated)
+				assert.Equal(t, expected.Sunset, route.Sunset)
+				assert.Equal(t, expected.Description, route.Description)
+				for param, constraint := range expected.Constraints {
+					assert.Equal(t, constraint, route.Constraints[param])
+				}
+			}
+		}
+
+		// optional path variant becomes a route
+		assert.Equal(t, "/v1/reports/:period", d.LookupByName("reports").Path)
+		assert.Equal(t, "min=3", d.LookupByName("reports").Constraints["period"])
+		assert.Equal(t, "/v1/reports", d.LookupByName("reports_1").Path)
+	}
+}
+
+func TestOpenAPIImportErrors(t *testing.T) {
+	d := (&router.Router{}).AddDomain("localhost", "8080")
+
+	_, err := Parse([]byte(`{"openapi": "2.0"}`))
+	assert.Equal(t, "openapi: unsupported version '2.0', supports OpenAPI 3", err.Error())
+
+	_, err = Parse([]byte("openapi: [3.0"))
+	assert.NotNil(t, err)
+
+	doc, err := Parse([]byte(`{"openapi": "3.0.3", "paths": {"/users": {"get": {"operationId": "listUsers"}}}}`))
+	assert.Nil(t, err)
+	err = Import(d, doc)
+	assert.Equal(t, "openapi: operation 'GET /users': operationId 'listUsers' is not in 'Controller.Action' format", err.Error())
+
+	doc, err = Parse([]byte(`openapi: 3.0.3
+paths:
+  /users/{id}:
+    get:
+      operationId: User.Show
+  /users/{name}:
+    get:
+      operationId: User.ShowByName
+`))
+	assert.Nil(t, err)
+	err = Import(d, doc)
+	assert.NotNil(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "openapi: "))
+
+	// document is imported all or nothing
+	assert.Equal(t, 0, len(d.Routes()))
+
+	doc, err = Parse([]byte(`openapi: 3.0.3
+paths:
+  /users:
+    get:
+      operationId: User.List
+      x-aah-sunset: tomorrow
+`))
+	assert.Nil(t, err)
+	err = Import(d, doc)
+	assert.Equal(t, "openapi: operation 'GET /users': x-aah-sunset value 'tomorrow' is invalid, it should be date '2006-01-02' or RFC3339 format", err.Error())
+
+	assert.Equal(t, ErrDomainIsNil, Import(nil, doc))
+}
+
+func TestOpenAPISchemaConstraint(t *testing.T) {
+	min, max := 3, 20
+	length := 36
+	one := 1.0
+	for expected, s := range map[string]*Schema{
+		"":                   nil,
+		"int":                {Type: "integer", Maximum: &one},
+		"uint":               {Type: "integer", Minimum: &one},
+		"uuid,len=36":        {Type: "string", Format: "uuid", MinLength: &length, MaxLength: &length},
+		"min=3,max=20,alpha": {Type: "string", MinLength: &min, MaxLength: &max, Pattern: "^[a-zA-Z]+$"},
+		"oneof=png jpg":      {Type: "string", Enum: []interface{}{"png", "jpg"}},
+		"regex([a-z0-9_-]+)": {Type: "string", Pattern: "^(?:[a-z0-9_-]+)$"},
+		"regex(^v[0-9]$)":    {Type: "string", Pattern: "^v[0-9]$"},
+		"min=3":              {Type: "number", Minimum: &one, MinLength: &min},
+		"slug":               {Type: "string", Pattern: "^[a-z0-9]+(?:-[a-z0-9]+)*$"},
+		"date":               {Type: "string", Format: "date"},
+		"len=3,alphanum":     {Type: "string", Pattern: "^[a-zA-Z0-9]+$", MinLength: &min, MaxLength: &min},
+	} {
+		assert.Equal(t, expected, schemaConstraint(s))
+	}
+
+	p, constraints := routePath("/users/{id}/files/{path}", []*Parameter{
+		{Name: "id", In: "path", Schema: &Schema{Type: "integer"}},
+		{Name: "path", In: "path", CatchAll: true},
+	})
+	assert.Equal(t, "/users/:id/files/*path", p)
+	assert.Equal(t, map[string]string{"id": "int"}, constraints)
+
+	// constraint is not parsed from path
+	p, constraints = routePath("/docs/{version}", []*Parameter{
+		{Name: "version", In: "path", Constraint: "regex(^v[0-9]/[a-z]+]$)"},
+	})
+	assert.Equal(t, "/docs/:version", p)
+	assert.Equal(t, map[string]string{"version": "regex(^v[0-9]/[a-z]+]$)"}, constraints)
+}
+
+func createHTTPRequest(path string) *http.Request {
+	return &http.Request{Method: ahttp.MethodGet, URL: &url.URL{Path: path}}
+}
@@ -0,0 +1,622 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/router source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package openapi generates the OpenAPI 3 document from the aah router
+// domain routes. Route path params are converted to OpenAPI path params,
+// for e.g.: `/users/:id` becomes `/users/{id}`, route constraints becomes
+// param schemas and route auth scheme becomes security requirement.
+//
+// aah route specific values are kept in the `x-aah-*` extensions, for e.g.:
+// controller, action, CORS and max body size.
+//
+// Also it imports the OpenAPI 3 document (JSON or YAML) into aah router domain,
+// refer to `Import` and `ImportFile`.
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"aahframework.org/ahttp.v0"
+	"aahframework.org/router.v0"
+	"gopkg.in/yaml.v2"
+)
+
+// Version is the OpenAPI specification version of generated document.
+const Version = "3.0.3"
+
+const (
+	methodWebSocket   = "WS"
+	authAnonymous     = "anonymous"
+	authAuthenticated = "authenticated"
+)
+
+// ErrDomainIsNil returned when given domain is nil.
+var ErrDomainIsNil = errors.New("openapi: domain is nil")
+
+// httpMethods is the OpenAPI path item operation names.
+var httpMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// OpenAPI document types
+//______________________________________________________________________________
+
+// Document is the OpenAPI 3 document.
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       *Info                 `json:"info"`
+	Servers    []*Server             `json:"servers,omitempty"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Components *Components           `json:"components,omitempty"`
+	operations map[string]*Operation // by operation id
+}
+
+// Info is the OpenAPI document info.
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// Server is the OpenAPI document server.
+type Server struct {
+	URL         string                     `json:"url"`
+	Description string                     `json:"description,omitempty"`
+	Variables   map[string]*ServerVariable `json:"variables,omitempty"`
+}
+
+// ServerVariable is the OpenAPI server URL variable.
+type ServerVariable struct {
+	Default     string `json:"default"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem holds the operations of the path by lowercase HTTP method.
+type PathItem map[string]*Operation
+
+// UnmarshalJSON method is json.Unmarshaler interface. It takes only the
+// operations from the path item, path level parameters are added into
+// each operation unless operation has the parameter.
+func (p *PathItem) UnmarshalJSON(b []byte) error {
+	var item map[string]json.RawMessage
+	if err := json.Unmarshal(b, &item); err != nil {
+		return err
+	}
+
+	var params []*Parameter
+	if raw, found := item["parameters"]; found {
+		if err := json.Unmarshal(raw, &params); err != nil {
+			return err
+		}
+	}
+
+	*p = make(PathItem)
+	for _, method := range httpMethods {
+		raw, found := item[method]
+		if !found {
+			continue
+		}
+
+		op := &Operation{}
+		if err := json.Unmarshal(raw, op); err != nil {
+			return fmt.Errorf("%s: %s", method, err)
+		}
+		for _, pp := range params {
+			if !op.hasParameter(pp.Name, pp.In) {
+				op.Parameters = append(op.Parameters, pp)
+			}
+		}
+		(*p)[method] = op
+	}
+	return nil
+}
+
+// Operation is the OpenAPI path operation, aah route specific values are
+// `x-aah-*` extensions.
+type Operation struct {
+	OperationID string                 `json:"operationId,omitempty"`
+	Summary     string                 `json:"summary,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Tags        []string               `json:"tags,omitempty"`
+	Deprecated  bool                   `json:"deprecated,omitempty"`
+	Parameters  []*Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody           `json:"requestBody,omitempty"`
+	Responses   map[string]*Response   `json:"responses"`
+	Security    *[]SecurityRequirement `json:"security,omitempty"`
+
+	RouteName     string      `json:"x-aah-route-name,omitempty"`
+	Controller    string      `json:"x-aah-controller,omitempty"`
+	Action        string      `json:"x-aah-action,omitempty"`
+	Auth          string      `json:"x-aah-auth,omitempty"`
+	CORS          *CORS       `json:"x-aah-cors,omitempty"`
+	MaxBodySize   interface{} `json:"x-aah-max-body-size,omitempty"`
+	AntiCSRFCheck *bool       `json:"x-aah-anti-csrf-check,omitempty"`
+	Owner         string      `json:"x-aah-owner,omitempty"`
+	Sunset        string      `json:"x-aah-sunset,omitempty"`
+}
+
+func (op *Operation) hasParameter(name, in string) bool {
+	for _, p := range op.Parameters {
+		if p.Name == name && p.In == in {
+			return true
+		}
+	}
+	return false
+}
+
+// Parameter is the OpenAPI operation parameter.
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required"`
+	Schema      *Schema `json:"schema,omitempty"`
+
+	Constraint string `json:"x-aah-constraint,omitempty"`
+	CatchAll   bool   `json:"x-aah-catch-all,omitempty"`
+}
+
+// Schema is the OpenAPI schema, it covers only the values used for path
+// param schemas and request body skeleton.
+type Schema struct {
+	Type             string        `json:"type,omitempty"`
+	Format           string        `json:"format,omitempty"`
+	Pattern          string        `json:"pattern,omitempty"`
+	Enum             []interface{} `json:"enum,omitempty"`
+	Default          interface{}   `json:"default,omitempty"`
+	Minimum          *float64      `json:"minimum,omitempty"`
+	Maximum          *float64      `json:"maximum,omitempty"`
+	ExclusiveMinimum bool          `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum bool          `json:"exclusiveMaximum,omitempty"`
+	MinLength        *int          `json:"minLength,omitempty"`
+	MaxLength        *int          `json:"maxLength,omitempty"`
+}
+
+// RequestBody is the OpenAPI operation request body.
+type RequestBody struct {
+	Description string                `json:"description,omitempty"`
+	Content     map[string]*MediaType `json:"content"`
+}
+
+// MediaType is the OpenAPI media type.
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// Response is the OpenAPI operation response.
+type Response struct {
+	Description string `json:"description"`
+}
+
+// Components is the OpenAPI document components.
+type Components struct {
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme is the OpenAPI security scheme.
+type SecurityScheme struct {
+	Type         string                 `json:"type"`
+	Description  string                 `json:"description,omitempty"`
+	Name         string                 `json:"name,omitempty"`
+	In           string                 `json:"in,omitempty"`
+	Scheme       string                 `json:"scheme,omitempty"`
+	BearerFormat string                 `json:"bearerFormat,omitempty"`
+	Flows        map[string]interface{} `json:"flows,omitempty"`
+}
+
+// SecurityRequirement is the OpenAPI security requirement.
+type SecurityRequirement map[string][]string
+
+// CORS is the aah route CORS extension `x-aah-cors`.
+type CORS struct {
+	AllowOrigins     []string `json:"allow_origins,omitempty"`
+	AllowMethods     []string `json:"allow_methods,omitempty"`
+	AllowHeaders     []string `json:"allow_headers,omitempty"`
+	ExposeHeaders    []string `json:"expose_headers,omitempty"`
+	AllowCredentials bool     `json:"allow_credentials,omitempty"`
+	MaxAge           string   `json:"max_age,omitempty"`
+}
+
+// Options is used to supply the document info and security schemes.
+// Auth scheme which is not found in the `SecuritySchemes` is documented as
+// HTTP bearer scheme, replace it as per application auth scheme.
+type Options struct {
+	Title           string
+	Description     string
+	Version         string
+	SecuritySchemes map[string]*SecurityScheme
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Package methods
+//______________________________________________________________________________
+
+// Generate method generates the OpenAPI document for given domain routes.
+// Static file and WebSocket routes are not included.
+func Generate(domain *router.Domain, opts *Options) (*Document, error) {
+	if domain == nil {
+		return nil, ErrDomainIsNil
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	doc := &Document{
+		OpenAPI: Version,
+		Info: &Info{
+			Title:       opts.Title,
+			Description: opts.Description,
+			Version:     opts.Version,
+		},
+		Servers:    []*Server{domainServer(domain)},
+		Paths:      make(map[string]PathItem),
+		operations: make(map[string]*Operation),
+	}
+	if len(doc.Info.Title) == 0 {
+		doc.Info.Title = domain.Name
+	}
+	if len(doc.Info.Version) == 0 {
+		doc.Info.Version = "1.0.0"
+	}
+
+	schemes := make(map[string]*SecurityScheme)
+	for _, route := range domain.Routes() {
+		if route.IsStatic || route.Method == methodWebSocket || route.IsAutoAdded() {
+			continue
+		}
+
+		op := routeOperation(domain, route)
+
+		// security requirement
+		auth := route.Auth
+		if auth == authAuthenticated {
+			auth = domain.DefaultAuth
+		}
+		switch auth {
+		case "", authAuthenticated:
+		case authAnonymous:
+			op.Security = &[]SecurityRequirement{}
+		default:
+			op.Security = &[]SecurityRequirement{{auth: []string{}}}
+			if _, found := schemes[auth]; !found {
+				schemes[auth] = securityScheme(auth, opts.SecuritySchemes)
+			}
+		}
+
+		for i, tokens := range expandOptionalPath(route.PathTokens()) {
+			opCopy := *op
+			if i > 0 {
+				// optional path variant is not a route on its own, so on import
+				// it becomes a route named by the operation id
+				opCopy.OperationID = op.OperationID + "_" + strconv.Itoa(i)
+				opCopy.RouteName = ""
+			}
+			opCopy.Parameters = pathParameters(route, tokens)
+
+			oasPath := convertPath(tokens)
+			item, found := doc.Paths[oasPath]
+			if !found {
+				item = make(PathItem)
+				doc.Paths[oasPath] = item
+			}
+			method := strings.ToLower(route.Method)
+			if _, found := item[method]; found {
+				return nil, fmt.Errorf("openapi: duplicate operation '%s %s'", route.Method, oasPath)
+			}
+			item[method] = &opCopy
+			doc.operations[opCopy.OperationID] = &opCopy
+		}
+	}
+
+	if len(schemes) > 0 {
+		doc.Components = &Components{SecuritySchemes: schemes}
+	}
+
+	return doc, nil
+}
+
+// GenerateAll method generates the OpenAPI document for each domain of the
+// router, documents are mapped by domain key.
+func GenerateAll(r *router.Router, opts *Options) (map[string]*Document, error) {
+	docs := make(map[string]*Document, len(r.Domains))
+	for _, d := range r.Domains {
+		doc, err := Generate(d, opts)
+		if err != nil {
+			return nil, err
+		}
+		docs[d.Key] = doc
+	}
+	return docs, nil
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Document methods
+//______________________________________________________________________________
+
+// Operation method returns the operation for given operation id otherwise nil.
+func (doc *Document) Operation(operationID string) *Operation {
+	return doc.operations[operationID]
+}
+
+// JSON method returns the document in JSON format.
+func (doc *Document) JSON() ([]byte, error) {
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// YAML method returns the document in YAML format.
+func (doc *Document) YAML() ([]byte, error) {
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v interface{}
+	if err = dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(jsonToYAMLValue(v))
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Unexported methods
+//______________________________________________________________________________
+
+func domainServer(domain *router.Domain) *Server {
+	scheme := domain.Scheme
+	if len(scheme) == 0 {
+		scheme = "http"
+	}
+
+	host := domain.Host
+	server := &Server{}
+	if strings.HasPrefix(host, "*.") {
+		host = "{subdomain}" + host[1:]
+		server.Variables = map[string]*ServerVariable{
+			"subdomain": {Default: "www", Description: "Wildcard subdomain"},
+		}
+	}
+
+	// host params, for e.g.: `:tenant.app.com` becomes `{tenant}.app.com`
+	labels := strings.Split(host, ".")
+	for i, label := range labels {
+		if len(label) > 1 && label[0] == ':' {
+			labels[i] = "{" + label[1:] + "}"
+			if server.Variables == nil {
+				server.Variables = make(map[string]*ServerVariable)
+			}
+			server.Variables[label[1:]] = &ServerVariable{Default: label[1:], Description: "Host param"}
+		}
+	}
+	host = strings.Join(labels, ".")
+	if len(domain.Port) > 0 {
+		host += ":" + domain.Port
+	}
+	server.URL = scheme + "://" + host
+	return server
+}
+
+func routeOperation(domain *router.Domain, route *router.Route) *Operation {
+	op := &Operation{
+		OperationID: route.Name,
+		Summary:     route.Description,
+		Tags:        route.Tags,
+		Deprecated:  route.Deprecated,
+		Responses:   map[string]*Response{"200": {Description: "OK"}},
+		RouteName:   route.Name,
+		Controller:  route.Target,
+		Action:      route.Action,
+		Auth:        route.Auth,
+		Owner:       route.Owner,
+	}
+
+	// multiple HTTP methods route has same name
+	if len(domain.RoutesByName(route.Name)) > 1 {
+		op.OperationID += "_" + strings.ToLower(route.Method)
+	}
+
+	if !route.Sunset.IsZero() {
+		op.Sunset = route.Sunset.Format("2006-01-02")
+	}
+
+	if route.IsAntiCSRFCheck {
+		op.AntiCSRFCheck = &route.IsAntiCSRFCheck
+	}
+
+	if route.CORS != nil {
+		op.CORS = &CORS{
+			AllowOrigins:     route.CORS.AllowOrigins,
+			AllowMethods:     route.CORS.AllowMethods,
+			AllowHeaders:     route.CORS.AllowHeaders,
+			ExposeHeaders:    route.CORS.ExposeHeaders,
+			AllowCredentials: route.CORS.AllowCredentials,
+			MaxAge:           route.CORS.MaxAge,
+		}
+	}
+
+	switch route.Method {
+	case ahttp.MethodPost, ahttp.MethodPut, ahttp.MethodPatch:
+		op.RequestBody = &RequestBody{
+			Content: map[string]*MediaType{
+				"application/json": {Schema: &Schema{Type: "object"}},
+			},
+		}
+		if route.MaxBodySize > 0 {
+			op.MaxBodySize = route.MaxBodySize
+		}
+	}
+
+	return op
+}
+
+func securityScheme(name string, schemes map[string]*SecurityScheme) *SecurityScheme {
+	if s, found := schemes[name]; found {
+		return s
+	}
+	return &SecurityScheme{
+		Type:        "http",
+		Scheme:      "bearer",
+		Description: fmt.Sprintf("aah auth scheme '%s'", name),
+	}
+}
+
+// pathParameters method returns the path parameters of given route path
+// tokens, it is route path or it's optional path variant.
+func pathParameters(route *router.Route, tokens []router.PathToken) []*Parameter {
+	var params []*Parameter
+	for _, t := range tokens {
+		if t.Param == nil {
+			continue
+		}
+
+		param := &Parameter{Name: t.Param.Name, In: "path", Required: true, CatchAll: t.Param.CatchAll}
+		if len(t.Param.Constraint) > 0 {
+			param.Constraint = t.Param.Constraint
+			param.Schema = constraintSchema(t.Param.ConstraintRules)
+		} else {
+			param.Schema = &Schema{Type: "string"}
+		}
+		if param.CatchAll {
+			param.Description = "Catch-all path value, it includes path separator '/'"
+		}
+		if v, found := route.Defaults[param.Name]; found {
+			param.Schema.Default = v
+		}
+		params = append(params, param)
+	}
+	return params
+}
+
+// constraintSchema method returns the param schema for aah route constraint
+// rules, constraint which does not have schema equivalent is documented as
+// string with extension `x-aah-constraint`.
+func constraintSchema(rules []string) *Schema {
+	s := &Schema{Type: "string"}
+	for _, rule := range rules {
+		name, arg := rule, ""
+		if idx := strings.IndexByte(rule, '='); idx > 0 {
+			name, arg = strings.TrimSpace(rule[:idx]), strings.TrimSpace(rule[idx+1:])
+		}
+
+		switch {
+		case name == "int":
+			s.Type, s.Format = "integer", "int64"
+		case name == "uint":
+			s.Type, s.Format = "integer", "int64"
+			s.Minimum = float64Ptr(0)
+		case name == "uuid":
+			s.Format = "uuid"
+		case name == "date":
+			s.Format = "date"
+		case name == "alpha":
+			s.Pattern = "^[a-zA-Z]+$"
+		case name == "alphanum":
+			s.Pattern = "^[a-zA-Z0-9]+$"
+		case name == "slug":
+			s.Pattern = "^[a-z0-9]+(?:-[a-z0-9]+)*$"
+		case name == "min" || name == "max" || name == "len" ||
+			name == "gt" || name == "gte" || name == "lt" || name == "lte":
+			// valpar compares the path param value length
+			v, err := strconv.Atoi(arg)
+			if err != nil {
+				continue
+			}
+			switch name {
+			case "gt":
+				s.MinLength = intPtr(v + 1)
+			case "lt":
+				s.MaxLength = intPtr(v - 1)
+			case "min", "gte":
+				s.MinLength = intPtr(v)
+			case "max", "lte":
+				s.MaxLength = intPtr(v)
+			default:
+				s.MinLength, s.MaxLength = intPtr(v), intPtr(v)
+			}
+		case name == "oneof":
+			s.Enum = nil
+			for _, v := range strings.Fields(arg) {
+				s.Enum = append(s.Enum, v)
+			}
+		case strings.HasPrefix(rule, "regex(") && strings.HasSuffix(rule, ")"):
+			s.Pattern = "^(?:" + rule[6:len(rule)-1] + ")$"
+		}
+	}
+	return s
+}
+
+// expandOptionalPath method returns the path variants of route path tokens
+// with optional params, full path is first. For e.g.: `/reports/:period?`
+// becomes `/reports/:period` and `/reports`.
+func expandOptionalPath(tokens []router.PathToken) [][]router.PathToken {
+	variants := [][]router.PathToken{tokens}
+	for i := len(tokens) - 1; i >= 0; i-- {
+		if tokens[i].Param == nil || !tokens[i].Param.Optional {
+			continue
+		}
+
+		// optional param is entire path segment, so preceding '/' is removed
+		variant := append([]router.PathToken{}, tokens[:i]...)
+		last := len(variant) - 1
+		if text := strings.TrimSuffix(variant[last].Text, "/"); len(text) > 0 {
+			variant[last].Text = text
+		} else if last > 0 {
+			variant = variant[:last]
+		}
+		variants = append(variants, variant)
+	}
+	return variants
+}
+
+// convertPath method converts the aah route path tokens into OpenAPI path,
+// for e.g.: `/users/:id` becomes `/users/{id}`.
+func convertPath(tokens []router.PathToken) string {
+	var buf strings.Builder
+	for _, t := range tokens {
+		if t.Param == nil {
+			buf.WriteString(t.Text)
+			continue
+		}
+		buf.WriteByte('{')
+		buf.WriteString(t.Param.Name)
+		buf.WriteByte('}')
+	}
+	return buf.String()
+}
+
+func float64Ptr(v float64) *float64 {
+	return &v
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+// jsonToYAMLValue method converts the JSON numbers of decoded value into
+// int64 or float64, so YAML has them as numbers, it is reverse of
+// `yamlToJSONValue`.
+func jsonToYAMLValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, v := range t {
+			t[k] = jsonToYAMLValue(v)
+		}
+	case []interface{}:
+		for i := range t {
+			t[i] = jsonToYAMLValue(t[i])
+		}
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i
+		}
+		f, _ := t.Float64()
+		return f
+	}
+	return v
+}
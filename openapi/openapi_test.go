@@ -0,0 +1,219 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/router source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package openapi
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"aahframework.org/ahttp.v0"
+	"aahframework.org/router.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestOpenAPIGenerate(t *testing.T) {
+	domain := createDomain(t)
+
+	doc, err := Generate(domain, &Options{
+		Title:   "Sample API",
+		Version: "2.0.0",
+		SecuritySchemes: map[string]*SecurityScheme{
+			"jwt": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, Version, doc.OpenAPI)
+	assert.Equal(t, "Sample API", doc.Info.Title)
+	assert.Equal(t, "2.0.0", doc.Info.Version)
+	assert.Equal(t, "https://api.sample.com:8443", doc.Servers[0].URL)
+
+	// path conversion and excluded routes
+	var paths []string
+	for p := range doc.Paths {
+		paths = append(paths, p)
+	}
+	assert.Equal(t, 6, len(paths))
+	for _, p := range []string{"/v1/users/{id}", "/v1/files/{filepath}", "/v1/images/{name}.{ext}",
+		"/v1/reports/{period}", "/v1/reports", "/v1/health"} {
+		_, found := doc.Paths[p]
+		assert.Truef(t, found, "path '%s'", p)
+	}
+
+	// operation, constraints and security
+	op := doc.Paths["/v1/users/{id}"]["get"]
+	assert.Equal(t, "user_show", op.OperationID)
+	assert.Equal(t, "Show the user", op.Summary)
+	assert.Equal(t, []string{"users"}, op.Tags)
+	assert.True(t, op.Deprecated)
+	assert.Equal(t, "2019-06-30", op.Sunset)
+	assert.Equal(t, "User", op.Controller)
+	assert.Equal(t, "Show", op.Action)
+	assert.Equal(t, "jwt", op.Auth)
+	assert.Equal(t, []SecurityRequirement{{"jwt": []string{}}}, *op.Security)
+	assert.Nil(t, op.RequestBody)
+	assert.Equal(t, 1, len(op.Parameters))
+	assert.Equal(t, "id", op.Parameters[0].Name)
+	assert.Equal(t, "path", op.Parameters[0].In)
+	assert.True(t, op.Parameters[0].Required)
+	assert.Equal(t, "int,gt=0", op.Parameters[0].Constraint)
+	assert.Equal(t, "integer", op.Parameters[0].Schema.Type)
+	assert.Nil(t, op.Parameters[0].Schema.Minimum)
+	assert.Equal(t, 1, *op.Parameters[0].Schema.MinLength)
+	assert.Equal(t, "JWT", doc.Components.SecuritySchemes["jwt"].BearerFormat)
+	assert.Equal(t, op, doc.Operation("user_show"))
+
+	// multiple HTTP methods route
+	op = doc.Paths["/v1/users/{id}"]["put"]
+	assert.Equal(t, "user_update_put", op.OperationID)
+	assert.Equal(t, int64(10485760), op.MaxBodySize)
+	assert.NotNil(t, op.RequestBody.Content["application/json"])
+	assert.Equal(t, "user_update_post", doc.Paths["/v1/users/{id}"]["post"].OperationID)
+
+	// numbers are kept as numbers in YAML
+	b, err := doc.YAML()
+	assert.Nil(t, err)
+	assert.True(t, strings.Contains(string(b), "x-aah-max-body-size: 10485760\n"))
+
+	// catch-all and anonymous
+	op = doc.Paths["/v1/files/{filepath}"]["get"]
+	assert.True(t, op.Parameters[0].CatchAll)
+	assert.Equal(t, []SecurityRequirement{}, *op.Security)
+
+	// multiple params in a path segment
+	op = doc.Paths["/v1/images/{name}.{ext}"]["get"]
+	assert.Equal(t, 2, len(op.Parameters))
+	assert.Equal(t, "^(?:[a-z0-9_-]+)$", op.Parameters[0].Schema.Pattern)
+	assert.Equal(t, []interface{}{"png", "jpg"}, op.Parameters[1].Schema.Enum)
+
+	// optional param
+	op = doc.Paths["/v1/reports/{period}"]["get"]
+	assert.Equal(t, "reports", op.OperationID)
+	assert.Equal(t, "daily", op.Parameters[0].Schema.Default)
+	assert.Equal(t, 3, *op.Parameters[0].Schema.MinLength)
+	assert.Equal(t, "reports_1", doc.Paths["/v1/reports"]["get"].OperationID)
+	assert.Nil(t, doc.Paths["/v1/reports"]["get"].Parameters)
+
+	// default security scheme for unknown auth scheme
+	op = doc.Paths["/v1/health"]["get"]
+	assert.Equal(t, []SecurityRequirement{{"form_auth": []string{}}}, *op.Security)
+	assert.Equal(t, "http", doc.Components.SecuritySchemes["form_auth"].Type)
+	assert.Equal(t, "aah auth scheme 'form_auth'", doc.Components.SecuritySchemes["form_auth"].Description)
+
+	_, err = Generate(nil, nil)
+	assert.Equal(t, ErrDomainIsNil, err)
+}
+
+func TestOpenAPIJSONAndYAML(t *testing.T) {
+	rtr := &router.Router{}
+	d := rtr.AddDomain("*.sample.com", "8080")
+	d.Group("/").Auth("anonymous").GET("/users/:id[uuid]", "User", "Show")
+	rtr.AddDomain(":tenant.app.com", "8080").Group("/").GET("/", "App", "Index")
+
+	docs, err := GenerateAll(rtr, nil)
+	assert.Nil(t, err)
+	doc := docs["*.sample.com:8080"]
+	assert.Equal(t, "*.sample.com", doc.Info.Title)
+	assert.Equal(t, "http://{subdomain}.sample.com:8080", doc.Servers[0].URL)
+	assert.Equal(t, "http://{tenant}.app.com:8080", docs[":tenant.app.com:8080"].Servers[0].URL)
+	assert.Equal(t, "tenant", docs[":tenant.app.com:8080"].Servers[0].Variables["tenant"].Default)
+
+	b, err := doc.JSON()
+	assert.Nil(t, err)
+	var v map[string]interface{}
+	assert.Nil(t, json.Unmarshal(b, &v))
+	assert.Equal(t, "3.0.3", v["openapi"])
+
+	b, err = doc.YAML()
+	assert.Nil(t, err)
+	assert.Equal(t, `info:
+  title: '*.sample.com'
+  version: 1.0.0
+openapi: 3.0.3
+paths:
+  /users/{id}:
+    get:
+      operationId: user_show
+      parameters:
+      - in: path
+        name: id
+        required: true
+        schema:
+          format: uuid
+          type: string
+        x-aah-constraint: uuid
+      responses:
+        "200":
+          description: OK
+      security: []
+      x-aah-action: Show
+      x-aah-anti-csrf-check: true
+      x-aah-auth: anonymous
+      x-aah-controller: User
+      x-aah-route-name: user_show
+servers:
+- url: http://{subdomain}.sample.com:8080
+  variables:
+    subdomain:
+      default: www
+      description: Wildcard subdomain
+`, string(b))
+}
+
+func TestOpenAPIConvertPath(t *testing.T) {
+	for p, expected := range map[string]string{
+		"/":                         "/",
+		"/users/:id":                "/users/{id}",
+		"/users/:id/posts/:post_id": "/users/{id}/posts/{post_id}",
+		"/static/*filepath":         "/static/{filepath}",
+		"/images/:name.:ext":        "/images/{name}.{ext}",
+	} {
+		assert.Equal(t, expected, convertPath((&router.Route{Path: p}).PathTokens()))
+	}
+
+	variants := func(p string) []string {
+		var paths []string
+		for _, tokens := range expandOptionalPath((&router.Route{Path: p}).PathTokens()) {
+			paths = append(paths, convertPath(tokens))
+		}
+		return paths
+	}
+	assert.Equal(t, []string{"/users"}, variants("/users"))
+	assert.Equal(t, []string{"/reports/{period}/{format}", "/reports/{period}", "/reports"},
+		variants("/reports/:period?/:format?"))
+	assert.Equal(t, []string{"/{lang}", "/"}, variants("/:lang?"))
+}
+
+func createDomain(t *testing.T) *router.Domain {
+	rtr := &router.Router{}
+	d := rtr.AddDomain("api.sample.com", "8443")
+	d.Scheme = "https"
+	d.DefaultAuth = "form_auth"
+
+	v1 := d.Group("/v1")
+	v1.Group("").Auth("jwt").Tags("users").Deprecated(time.Date(2019, 6, 30, 0, 0, 0, 0, time.UTC)).
+		GET("/users/:id[int,gt=0]", "User", "Show").
+		Group("").MaxBodySize("10mb").
+		Handle("user_update", "POST,PUT", "/users/:id[int,gt=0]", "User", "Update")
+	v1.Group("").Auth("anonymous").GET("/files/*filepath", "File", "Serve")
+	v1.GET("/images/:name[regex([a-z0-9_-]+)].:ext[oneof=png jpg]", "Image", "Show").
+		GET("/health", "App", "Health").
+		WS("/events", "Events", "Handle")
+	assert.Nil(t, v1.Err())
+
+	assert.Nil(t, d.AddRoute(&router.Route{
+		Name:        "reports",
+		Path:        "/v1/reports/:period?",
+		Method:      ahttp.MethodGet,
+		Target:      "Report",
+		Action:      "Show",
+		Constraints: map[string]string{"period": "min=3"},
+		Defaults:    map[string]string{"period": "daily"},
+	}))
+
+	d.LookupByName("user_show").Description = "Show the user"
+	return d
+}
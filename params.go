@@ -0,0 +1,114 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/router source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package router
+
+import (
+	"sync"
+
+	"aahframework.org/ahttp.v0"
+)
+
+var paramsBufferPool = &sync.Pool{New: func() interface{} { return &ParamsBuffer{} }}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Package methods
+//______________________________________________________________________________
+
+// AcquireParamsBuffer method gets from pool or creates an `ParamsBuffer`
+// instance.
+func AcquireParamsBuffer() *ParamsBuffer {
+	return paramsBufferPool.Get().(*ParamsBuffer)
+}
+
+// ReleaseParamsBuffer method resets and puts the given params buffer into pool.
+func ReleaseParamsBuffer(pb *ParamsBuffer) {
+	if pb != nil {
+		pb.Reset()
+		paramsBufferPool.Put(pb)
+	}
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// ParamsBuffer
+//______________________________________________________________________________
+
+// ParamsBuffer holds the path parameters as key-value pairs in the order of
+// route path, it is used by `Domain.LookupInto` to capture the path parameters
+// without allocation. Buffer is sized based on the route tree, so it is
+// reused across the lookups.
+//
+// Note: Path parameter values refers the request path, use the values within
+// request life cycle or copy it.
+type ParamsBuffer struct {
+	params []pathParam
+}
+
+// Len method returns the count of path parameters.
+func (pb *ParamsBuffer) Len() int {
+	return len(pb.params)
+}
+
+// Key method returns the path parameter key at given index.
+func (pb *ParamsBuffer) Key(i int) string {
+	return pb.params[i].key
+}
+
+// Value method returns the path parameter value at given index.
+func (pb *ParamsBuffer) Value(i int) string {
+	return pb.params[i].value
+}
+
+// Get method returns the value for the given key otherwise empty string.
+func (pb *ParamsBuffer) Get(key string) string {
+	for i := range pb.params {
+		if pb.params[i].key == key {
+			return pb.params[i].value
+		}
+	}
+	return ""
+}
+
+// PathParams method returns the path parameters as `ahttp.PathParams`,
+// it allocates the map.
+func (pb *ParamsBuffer) PathParams() ahttp.PathParams {
+	if len(pb.params) == 0 {
+		return nil
+	}
+
+	pathParams := make(ahttp.PathParams, len(pb.params))
+	for _, p := range pb.params {
+		pathParams[p.key] = p.value
+	}
+	return pathParams
+}
+
+// Reset method resets the params buffer for reuse, underlying storage
+// is retained.
+func (pb *ParamsBuffer) Reset() {
+	pb.params = pb.params[:0]
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// ParamsBuffer unexported methods
+//______________________________________________________________________________
+
+// grow method ensures the capacity of buffer for given no. of params.
+func (pb *ParamsBuffer) grow(n int) {
+	if cap(pb.params) < n {
+		params := make([]pathParam, len(pb.params), n)
+		copy(params, pb.params)
+		pb.params = params
+	}
+}
+
+// add method adds the given key and value if key not exists.
+func (pb *ParamsBuffer) add(key, value string) {
+	for i := range pb.params {
+		if pb.params[i].key == key {
+			return
+		}
+	}
+	pb.params = append(pb.params, pathParam{key: key, value: value})
+}
@@ -23,10 +23,11 @@ const (
 	// SlashString const for comparison use
 	SlashString = "/"
 
-	dotByte   = '.'
-	slashByte = '/'
-	paramByte = ':'
-	wildByte  = '*'
+	dotByte      = '.'
+	slashByte    = '/'
+	paramByte    = ':'
+	wildByte     = '*'
+	optionalByte = '?'
 )
 
 const (
@@ -42,15 +43,50 @@ var (
 
 type nodeType uint8
 
+// lookupFlag is used to control the value lookup in the tree.
+type lookupFlag uint8
+
+const (
+	// checkConstraints validates the path parameter constraints
+	checkConstraints lookupFlag = 1 << iota
+
+	// escapedPath indicates path is escaped, path parameter values are
+	// unescaped
+	escapedPath
+)
+
+// node is a radix tree node. Static edges are kept first in the `edges` and
+// indexed by their first byte in `indices`, followed by wildcard edges.
+//
+// Static edges take precedence over wildcard edges, wildcard edges are tried
+// as a fallback in priority order, param edges with constraint
+// first (ordered by constraint rank, then in the order of registration), then
+// param edge without constraint and finally catch-all edge.
 type node struct {
-	wildChild bool
-	nType     nodeType
-	maxParams uint8
-	priority  uint32
-	path      string
-	indices   string
-	edges     []*node
-	value     interface{}
+	nType      nodeType
+	maxParams  uint8
+	priority   uint32
+	path       string
+	indices    string
+	edges      []*node
+	constraint *constraint
+	value      interface{}
+}
+
+// pathParam holds the single path parameter key and value, captured while
+// walking the tree.
+type pathParam struct {
+	key   string
+	value string
+}
+
+// pathToken holds the parsed portion of route path, it could be static text,
+// param or catch-all.
+type pathToken struct {
+	nType    nodeType
+	optional bool
+	path     string
+	pos      int
 }
 
 // increments priority of the given edge and reorders if necessary
@@ -79,384 +115,387 @@ func (n *node) incrementEdgePriority(pos int) int {
 // add adds a node with the given value againsts given path.
 // Not concurrency-safe!
 func (n *node) add(path string, value interface{}) error {
-	fullPath := path
-	n.priority++
-	numParams := countParams(path)
+	return n.insert(path, value, nil)
+}
 
-	// non-empty tree
-	if len(n.path) > 0 || len(n.edges) > 0 {
-	walk:
-		for {
-			// Update maxParams of the current node
-			if numParams > n.maxParams {
-				n.maxParams = numParams
-			}
+// insert adds a node with the given value and path parameter constraints
+// againsts given path. Not concurrency-safe!
+//
+// Path with optional params adds the value for each optional segment omitted,
+// for e.g.: `/reports/:period?` adds `/reports` and `/reports/:period`.
+func (n *node) insert(path string, value interface{}, constraints map[string]*constraint) error {
+	tokens, err := parsePathTokens(path)
+	if err != nil {
+		return err
+	}
 
-			// Find the longest common prefix.
-			// This also implies that the common prefix contains no ':' or '*'
-			// since the existing key can't contain those chars.
-			i := 0
-			max := min(len(path), len(n.path))
-			for i < max && path[i] == n.path[i] {
-				i++
+	if strings.IndexByte(path, optionalByte) > 0 {
+		for _, t := range tokens {
+			if !t.optional {
+				continue
+			}
+			if err = n.insert(optionalPathPrefix(path, t), value, constraints); err != nil {
+				return err
 			}
+		}
 
-			// Split edge
-			if i < len(n.path) {
-				edge := node{
-					path:      n.path[i:],
-					wildChild: n.wildChild,
-					nType:     static,
-					indices:   n.indices,
-					priority:  n.priority - 1,
-					edges:     n.edges,
-					value:     n.value,
-				}
+		path = strings.Replace(path, string(optionalByte), "", -1)
+		if tokens, err = parsePathTokens(path); err != nil {
+			return err
+		}
+	}
 
-				// Update maxParams (max of all edges)
-				for i := range edge.edges {
-					if edge.edges[i].maxParams > edge.maxParams {
-						edge.maxParams = edge.edges[i].maxParams
-					}
-				}
+	numParams := countParams(path)
+	n.nType = root
+	n.priority++
+	n.updateMaxParams(numParams)
+
+	for _, t := range tokens {
+		switch t.nType {
+		case static:
+			n, err = n.insertStatic(t, path, numParams)
+		case param:
+			n, err = n.insertWildcard(t, path, numParams, constraints[t.path[1:]])
+			numParams--
+		case catchAll:
+			n, err = n.insertWildcard(t, path, numParams, constraints[t.path[2:]])
+			numParams--
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if n.value != nil {
+		return fmt.Errorf("a value is already registered for path '%s'", path)
+	}
+	n.value = value
+
+	return nil
+}
 
-				n.edges = []*node{&edge}
-				// []byte for proper unicode char conversion
-				n.indices = string([]byte{n.path[i]})
-				n.path = path[:i]
-				n.value = nil
-				n.wildChild = false
+// insertStatic walks or creates the static edges for the given token, it
+// returns the node which matches the end of token.
+func (n *node) insertStatic(t pathToken, fullPath string, numParams uint8) (*node, error) {
+	path := t.path
+walk:
+	for len(path) > 0 {
+		c := path[0]
+
+		// Check if a static edge with the common prefix exists
+		for i := 0; i < len(n.indices); i++ {
+			if c != n.indices[i] {
+				continue
 			}
 
-			// Make new node a edge of this node
-			if i < len(path) {
-				path = path[i:]
-
-				if n.wildChild {
-					n = n.edges[0]
-					n.priority++
-
-					// Update maxParams of the edge node
-					if numParams > n.maxParams {
-						n.maxParams = numParams
-					}
-					numParams--
-
-					// Check if the wildcard matches
-					if len(path) >= len(n.path) && n.path == path[:len(n.path)] &&
-						// Check for longer wildcard, e.g. :name and :names
-						(len(n.path) >= len(path) || path[len(n.path)] == slashByte) {
-						continue walk
-					} else {
-						// Wildcard conflict
-						var pathSeg string
-						if n.nType == catchAll {
-							pathSeg = path
-						} else {
-							pathSeg = strings.SplitN(path, SlashString, 2)[0]
-						}
-						prefix := fullPath[:strings.Index(fullPath, pathSeg)] + n.path
-
-						return fmt.Errorf("'%s' in new path '%s' conflicts with existing "+
-							"wildcard '%s' in existing prefix '%s'", pathSeg, fullPath, n.path, prefix)
-					}
-				}
+			edge := n.edges[i]
+			l := longestCommonPrefix(path, edge.path)
+			if l == 0 {
+				continue
+			}
 
-				c := path[0]
+			n = n.edges[n.incrementEdgePriority(i)]
+			n.updateMaxParams(numParams)
 
-				// slash after param
-				if n.nType == param && c == slashByte && len(n.edges) == 1 {
-					n = n.edges[0]
-					n.priority++
-					continue walk
-				}
+			// Split edge
+			if l < len(n.path) {
+				n.split(l)
+			}
 
-				// Check if a edge with the next path byte exists
-				for i = 0; i < len(n.indices); i++ {
-					if c == n.indices[i] {
-						i = n.incrementEdgePriority(i)
-						n = n.edges[i]
-						continue walk
-					}
-				}
+			path = path[l:]
+			continue walk
+		}
 
-				// Otherwise insert it
-				if c != paramByte && c != wildByte {
-					// []byte for proper unicode char conversion
-					n.indices += string([]byte{c})
-					edge := &node{
-						maxParams: numParams,
-					}
-					n.edges = append(n.edges, edge)
-					n.incrementEdgePriority(len(n.indices) - 1)
-					n = edge
-				}
-				if err := n.insertEdge(numParams, path, fullPath, value); err != nil {
-					return err
-				}
+		// Otherwise insert it, static edges are kept before the wildcard
+		// edges, since static takes precedence over wildcard
+		edge := &node{path: path, maxParams: numParams}
+		n.edges = append(n.edges, nil)
+		copy(n.edges[len(n.indices)+1:], n.edges[len(n.indices):])
+		n.edges[len(n.indices)] = edge
+		// []byte for proper unicode char conversion
+		n.indices += string([]byte{c})
+		return n.edges[n.incrementEdgePriority(len(n.indices)-1)], nil
+	}
 
-				return nil
+	return n, nil
+}
 
-			} else if i == len(path) { // Make node a (in-path) leaf
-				if n.value != nil {
-					return fmt.Errorf("a value is already registered for path '%s'", fullPath)
-				}
+// insertWildcard finds or creates the wildcard edge for the given token.
+// Wildcard edges co-exist with static edges, static edges are tried first
+// while finding the value.
+func (n *node) insertWildcard(t pathToken, fullPath string, numParams uint8, c *constraint) (*node, error) {
+	pos := len(n.edges)
+	for i, edge := range n.wildEdges() {
+		if edge.nType == t.nType && edge.path == t.path && edge.constraint.isEqual(c) {
+			edge.priority++
+			edge.updateMaxParams(numParams)
+			return edge, nil
+		}
 
-				n.value = value
+		switch {
+		case edge.nType == catchAll:
+			// only one catch-all is allowed, it's always the last edge
+			if t.nType == catchAll {
+				return nil, wildcardConflictError(t.path, fullPath, edge, fullPath[:t.pos])
+			}
+			if pos == len(n.edges) {
+				pos = len(n.indices) + i
+			}
+		case t.nType == param:
+			// Param edges with constraint are disambiguated by their
+			// constraints, only one param edge without constraint is allowed.
+			if c == nil && edge.constraint == nil {
+				return nil, wildcardConflictError(t.path, fullPath, edge, fullPath[:t.pos])
+			}
+			if c != nil && pos == len(n.edges) &&
+				(edge.constraint == nil || edge.constraint.rank > c.rank) {
+				pos = len(n.indices) + i
 			}
-			return nil
-		}
-	} else { // Empty tree
-		if err := n.insertEdge(numParams, path, fullPath, value); err != nil {
-			return err
 		}
+	}
 
-		n.nType = root
+	edge := &node{
+		nType:      t.nType,
+		path:       t.path,
+		constraint: c,
+		maxParams:  numParams,
+		priority:   1,
 	}
+	n.edges = append(n.edges, nil)
+	copy(n.edges[pos+1:], n.edges[pos:])
+	n.edges[pos] = edge
 
-	return nil
+	return edge, nil
 }
 
-func (n *node) insertEdge(numParams uint8, path, fullPath string, value interface{}) error {
-	var offset int // already handled bytes of the path
+// clone returns the deep copy of the node, route values are shared. It
+// returns nil for nil node.
+func (n *node) clone() *node {
+	if n == nil {
+		return nil
+	}
+	c := *n
+	if len(n.edges) > 0 {
+		c.edges = make([]*node, len(n.edges))
+		for i, edge := range n.edges {
+			c.edges[i] = edge.clone()
+		}
+	}
+	return &c
+}
 
-	// find prefix until first wildcard (beginning with ':'' or '*'')
-	for i, max := 0, len(path); numParams > 0; i++ {
-		c := path[i]
-		if c != paramByte && c != wildByte {
-			continue
+// split splits the static node at given index, remaining part of the node
+// becomes an edge.
+func (n *node) split(i int) {
+	edge := &node{
+		path:     n.path[i:],
+		nType:    static,
+		indices:  n.indices,
+		priority: n.priority - 1,
+		edges:    n.edges,
+		value:    n.value,
+	}
+
+	// Update maxParams (max of all edges)
+	for i := range edge.edges {
+		if edge.edges[i].maxParams > edge.maxParams {
+			edge.maxParams = edge.edges[i].maxParams
 		}
+	}
 
-		// find wildcard end (either '/' or path end)
-		end := i + 1
-		for end < max && path[end] != slashByte {
-			switch path[end] {
-			// the wildcard name must not contain ':' and '*'
-			case paramByte, wildByte:
-				return fmt.Errorf("only one wildcard per path segment is allowed, "+
-					"has: '%s' in path '%s'", path[i:], fullPath)
-			default:
-				end++
+	n.edges = []*node{edge}
+	// []byte for proper unicode char conversion
+	n.indices = string([]byte{edge.path[0]})
+	n.path = n.path[:i]
+	n.value = nil
+}
+
+func (n *node) updateMaxParams(numParams uint8) {
+	if numParams > n.maxParams {
+		n.maxParams = numParams
+	}
+}
+
+func (n *node) wildEdges() []*node {
+	return n.edges[len(n.indices):]
+}
+
+// find returns the value registered with the given path (key). The values of
+// wildcards are saved to a map. If no value can be found, a TSR (trailing slash
+// redirect) recommendation is made if a value exists with an extra (without
+// the) trailing slash for the given path. Path parameter constraints are
+// always validated.
+func (n *node) find(path string, flags lookupFlag) (value interface{}, p ahttp.PathParams, tsr bool, err error) {
+	flags |= checkConstraints
+	if value, p, err = n.lookup(path, flags); value != nil || err != nil {
+		return
+	}
+
+	// Nothing found. We can recommend to redirect to the same URL with or
+	// without trailing slash if a leaf exists for that path.
+	var params []pathParam
+	if len(path) > 1 && path[len(path)-1] == slashByte {
+		value, err = n.match(path[:len(path)-1], &params, flags)
+	} else {
+		value, err = n.match(path+SlashString, &params, flags)
+	}
+	tsr = value != nil
+	value = nil
+
+	return
+}
+
+// lookup returns the value registered with the given path (key) and path
+// parameters. Path parameter constraints are validated when flag
+// `checkConstraints` is set and path parameter values are unescaped when flag
+// `escapedPath` is set.
+func (n *node) lookup(path string, flags lookupFlag) (interface{}, ahttp.PathParams, error) {
+	var params []pathParam
+	value, err := n.match(path, &params, flags)
+	if value == nil || err != nil {
+		return nil, nil, err
+	}
+
+	var p ahttp.PathParams
+	if len(params) > 0 {
+		p = make(ahttp.PathParams, len(params))
+		for _, pp := range params {
+			if flags&escapedPath != 0 {
+				pp.value = unescapePathValue(pp.value)
 			}
+			p[pp.key] = pp.value
 		}
+	}
+
+	return value, p, nil
+}
 
-		// check if this Node existing edges which would be
-		// unreachable if we insert the wildcard here
-		if len(n.edges) > 0 {
-			return fmt.Errorf("wildcard route '%s' conflicts with existing"+
-				" edges in path '%s'", path[i:end], fullPath)
+// match walks the tree recursively for the given path, static edges are
+// tried first then wildcard edges. On mismatch it backtracks to next
+// possible edge.
+func (n *node) match(path string, params *[]pathParam, flags lookupFlag) (interface{}, error) {
+	mark := len(*params)
+
+	switch n.nType {
+	case static, root:
+		if len(path) < len(n.path) || path[:len(n.path)] != n.path {
+			return nil, nil
 		}
 
-		// check if the wildcard has a name
-		if end-i < 2 {
-			return fmt.Errorf("wildcards must be named with a non-empty name"+
-				" in path '%s'", fullPath)
+		if path = path[len(n.path):]; len(path) == 0 {
+			return n.value, nil
 		}
 
-		if c == paramByte { // param
-			// split path at the beginning of the wildcard
-			if i > 0 {
-				n.path = path[offset:i]
-				offset = i
+	case param:
+		for _, end := range n.paramEnds(path, false) {
+			if end <= 0 {
+				continue
 			}
 
-			edge := &node{
-				nType:     param,
-				maxParams: numParams,
+			if flags&checkConstraints != 0 && n.constraint != nil &&
+				!n.constraint.isSatisfied(path[:end], flags&escapedPath != 0) {
+				continue
 			}
-			n.edges = []*node{edge}
-			n.wildChild = true
-			n = edge
-			n.priority++
-			numParams--
 
-			// if the path doesn't end with the wildcard, then there
-			// will be another non-wildcard subpath starting with '/'
-			if end < max {
-				n.path = path[offset:end]
-				offset = end
+			// add path param value
+			*params = append(*params, pathParam{key: n.path[1:], value: path[:end]})
 
-				edge := &node{
-					maxParams: numParams,
-					priority:  1,
+			if end == len(path) {
+				if n.value != nil {
+					return n.value, nil
 				}
-				n.edges = []*node{edge}
-				n = edge
+			} else if value, err := n.matchEdges(path[end:], params, flags); value != nil || err != nil {
+				return value, err
 			}
 
-		} else { // catchAll
-			if end != max || numParams > 1 {
-				return fmt.Errorf("catch-all routes are only allowed at the end of"+
-					" the path in path '%s'", fullPath)
-			}
+			*params = (*params)[:mark]
+		}
 
-			if len(n.path) > 0 && n.path[len(n.path)-1] == slashByte {
-				return fmt.Errorf("catch-all conflicts with existing value for the"+
-					" path segment root in path '%s'", fullPath)
-			}
+		return nil, nil
 
-			// currently fixed width 1 for '/'
-			i--
-			if path[i] != slashByte {
-				return fmt.Errorf("no / before catch-all in path '%s'", fullPath)
-			}
+	case catchAll:
+		if len(path) == 0 || path[0] != slashByte || n.value == nil {
+			return nil, nil
+		}
 
-			n.path = path[offset:i]
+		// catch-all constraint is validated without leading slash
+		if flags&checkConstraints != 0 && n.constraint != nil &&
+			!n.constraint.isSatisfied(path[1:], flags&escapedPath != 0) {
+			return nil, nil
+		}
 
-			// first node: catchAll node with empty path
-			edge := &node{
-				wildChild: true,
-				nType:     catchAll,
-				maxParams: 1,
-			}
-			n.edges = []*node{edge}
-			n.indices = string(path[i])
-			n = edge
-			n.priority++
-
-			// second node: node holding the variable
-			edge = &node{
-				path:      path[i:],
-				nType:     catchAll,
-				maxParams: 1,
-				priority:  1,
-				value:     value,
-			}
-			n.edges = []*node{edge}
+		// add path param value
+		*params = append(*params, pathParam{key: n.path[2:], value: path})
+		return n.value, nil
 
-			return nil
-		}
+	default:
+		return nil, errInvalidNodeType
 	}
 
-	// insert remaining path part and value to the leaf
-	n.path = path[offset:]
-	n.value = value
-
-	return nil
+	return n.matchEdges(path, params, flags)
 }
 
-// find returns the value registered with the given path (key). The values of
-// wildcards are saved to a map. If no value can be found, a TSR (trailing slash
-// redirect) recommendation is made if a value exists with an extra (without
-// the) trailing slash for the given path.
-func (n *node) find(path string) (value interface{}, p ahttp.PathParams, tsr bool, err error) {
-walk: // outer loop for walking the tree
-	for {
-		if len(path) > len(n.path) {
-			if path[:len(n.path)] == n.path {
-				path = path[len(n.path):]
-				// If this node does not have a wildcard (param or catchAll)
-				// edge,  we can just look up the next edge node and continue
-				// to walk down the tree
-				if !n.wildChild {
-					c := path[0]
-					for i := 0; i < len(n.indices); i++ {
-						if c == n.indices[i] {
-							n = n.edges[i]
-							continue walk
-						}
-					}
-
-					// Nothing found.
-					// We can recommend to redirect to the same URL without a
-					// trailing slash if a leaf exists for that path.
-					tsr = (path == SlashString && n.value != nil)
-					return
-
-				}
-
-				// value wildcard edge
-				n = n.edges[0]
-				switch n.nType {
-				case param:
-					// find param end (either '/' or path end)
-					end := 0
-					for end < len(path) && path[end] != slashByte {
-						end++
-					}
-
-					// save param value
-					if p == nil {
-						// lazy allocation
-						p = make(ahttp.PathParams, n.maxParams)
-					}
-					// add path param value
-					p[n.path[1:]] = path[:end]
-
-					// we need to go deeper!
-					if end < len(path) {
-						if len(n.edges) > 0 {
-							path = path[end:]
-							n = n.edges[0]
-							continue walk
-						}
-
-						// ... but we can't
-						tsr = (len(path) == end+1)
-						return
-					}
-
-					if value = n.value; value != nil {
-						return
-					} else if len(n.edges) == 1 {
-						// No value found. Check if a value for this path + a
-						// trailing slash exists for TSR recommendation
-						n = n.edges[0]
-						tsr = (n.path == SlashString && n.value != nil)
-					}
-
-					return
-
-				case catchAll:
-					// save param value
-					if p == nil {
-						// lazy allocation
-						p = make(ahttp.PathParams, n.maxParams)
-					}
-					// add path param value
-					p[n.path[2:]] = path
-
-					value = n.value
-					return
-
-				default:
-					err = errInvalidNodeType
-					return
-				}
-			}
-		} else if path == n.path {
-			// We should have reached the node containing the value.
-			// Check if this node has a value registered.
-			if value = n.value; value != nil {
-				return
+// matchEdges tries the static edges first then wildcard edges for the given
+// remaining path.
+func (n *node) matchEdges(path string, params *[]pathParam, flags lookupFlag) (interface{}, error) {
+	mark := len(*params)
+
+	// static edges
+	c := path[0]
+	for i := 0; i < len(n.indices); i++ {
+		if c == n.indices[i] {
+			if value, err := n.edges[i].match(path, params, flags); value != nil || err != nil {
+				return value, err
 			}
+		}
+	}
 
-			if path == SlashString && n.wildChild && n.nType != root {
-				tsr = true
-				return
-			}
+	// wildcard edges
+	for _, edge := range n.wildEdges() {
+		if value, err := edge.match(path, params, flags); value != nil || err != nil {
+			return value, err
+		}
+	}
 
-			// No value found. Check if a value for this path + a
-			// trailing slash exists for trailing slash recommendation
-			for i := 0; i < len(n.indices); i++ {
-				if n.indices[i] == slashByte {
-					n = n.edges[i]
-					tsr = (len(n.path) == 1 && n.value != nil) ||
-						(n.nType == catchAll && n.edges[0].value != nil)
-					return
-				}
-			}
+	*params = (*params)[:mark]
+	return nil, nil
+}
 
-			return
+// paramEnds returns the param value end positions to try within the path
+// segment, at most 3 candidates per param -
+//  1. last occurrence of a static edge start byte (longest value)
+//  2. first occurrence of a static edge start byte
+//  3. end of the path segment
+//
+// Occurrences in between are never tried. For e.g.: route `/:a-:b[len=3]`
+// does not match `/w-x-y-z`, since value `w-x` for `a` is not tried. It keeps
+// the backtracking to 3 tries per param instead of one per occurrence.
+// Position 0 is not a valid end, it is returned for the absent candidate.
+func (n *node) paramEnds(path string, fold bool) [3]int {
+	first, last, segEnd := 0, 0, 0
+	for ; segEnd < len(path) && path[segEnd] != slashByte; segEnd++ {
+		if segEnd > 0 && n.isEdgeStart(path[segEnd], fold) {
+			if first == 0 {
+				first = segEnd
+			}
+			last = segEnd
 		}
+	}
+	if first == last {
+		first = 0
+	}
+	return [3]int{last, first, segEnd}
+}
 
-		// Nothing found. We can recommend to redirect to the same URL with an
-		// extra trailing slash if a leaf exists for that path
-		tsr = (path == SlashString) ||
-			(len(n.path) == len(path)+1 && n.path[len(path)] == slashByte &&
-				path == n.path[:len(n.path)-1] && n.value != nil)
-		return
+// isEdgeStart returns true if any static edge starts with the given byte.
+func (n *node) isEdgeStart(c byte, fold bool) bool {
+	for i := 0; i < len(n.indices); i++ {
+		if c == n.indices[i] || (fold && c < utf8.RuneSelf && n.indices[i] < utf8.RuneSelf &&
+			equalFoldRune(rune(c), rune(n.indices[i]))) {
+			return true
+		}
 	}
+	return false
 }
 
 // Makes a case-insensitive lookup of the given path and tries to find a handler.
@@ -464,206 +503,268 @@ walk: // outer loop for walking the tree
 // It returns the case-corrected path and a bool indicating whether the lookup
 // was successful.
 func (n *node) findCaseInsensitive(path string, fixTrailingSlash bool) (string, bool, error) {
-	ciPath, found, err := n.findCaseInsensitiveRec(
-		path,
-		strings.ToLower(path),
-		make([]byte, 0, len(path)+1), // preallocate enough memory for new path
-		[4]byte{},                    // empty rune buffer
-		fixTrailingSlash,
-	)
-
-	return string(ciPath), found, err
-}
-
-// shift bytes in array by n bytes left
-func shiftNRuneBytes(rb [4]byte, n int) [4]byte {
-	switch n {
-	case 0:
-		return rb
-	case 1:
-		return [4]byte{rb[1], rb[2], rb[3], 0}
-	case 2:
-		return [4]byte{rb[2], rb[3]}
-	case 3:
-		return [4]byte{rb[3]}
-	default:
-		return [4]byte{}
+	ciPath := make([]byte, 0, len(path)+1) // preallocate enough memory for new path
+	out, found, err := n.findCaseInsensitiveRec(path, ciPath)
+	if found || err != nil || !fixTrailingSlash {
+		return string(out), found, err
 	}
+
+	// Try to fix the path by adding / removing a trailing slash
+	if len(path) > 1 && path[len(path)-1] == slashByte {
+		out, found, err = n.findCaseInsensitiveRec(path[:len(path)-1], ciPath)
+	} else {
+		out, found, err = n.findCaseInsensitiveRec(path+SlashString, ciPath)
+	}
+
+	return string(out), found, err
 }
 
 // recursive case-insensitive lookup function used by n.findCaseInsensitive
-func (n *node) findCaseInsensitiveRec(path, loPath string, ciPath []byte, rb [4]byte, fixTrailingSlash bool) ([]byte, bool, error) {
-	loNPath := strings.ToLower(n.path)
+func (n *node) findCaseInsensitiveRec(path string, ciPath []byte) ([]byte, bool, error) {
+	switch n.nType {
+	case static, root:
+		l := foldPrefixLen(path, n.path)
+		if l == -1 {
+			return ciPath, false, nil
+		}
 
-walk: // outer loop for walking the tree
-	for len(loPath) >= len(loNPath) && (len(loNPath) == 0 || loPath[1:len(loNPath)] == loNPath[1:]) {
 		// add common path to result
 		ciPath = append(ciPath, n.path...)
+		if path = path[l:]; len(path) == 0 {
+			return ciPath, n.value != nil, nil
+		}
 
-		if path = path[len(n.path):]; len(path) > 0 {
-			loOld := loPath
-			loPath = loPath[len(loNPath):]
-
-			// If this node does not have a wildcard (param or catchAll) edge,
-			// we can just look up the next edge node and continue to walk down
-			// the tree
-			if !n.wildChild {
-				// skip rune bytes already processed
-				rb = shiftNRuneBytes(rb, len(loNPath))
-
-				if rb[0] != 0 {
-					// old rune not finished
-					for i := 0; i < len(n.indices); i++ {
-						if n.indices[i] == rb[0] {
-							// continue with edge node
-							n = n.edges[i]
-							loNPath = strings.ToLower(n.path)
-							continue walk
-						}
-					}
-				} else {
-					// process a new rune
-					var rv rune
-
-					// find rune start
-					// runes are up to 4 byte long,
-					// -4 would definitely be another rune
-					var off int
-					for max := min(len(loNPath), 3); off < max; off++ {
-						if i := len(loNPath) - off; utf8.RuneStart(loOld[i]) {
-							// read rune from cached lowercase path
-							rv, _ = utf8.DecodeRuneInString(loOld[i:])
-							break
-						}
-					}
-
-					// calculate lowercase bytes of current rune
-					utf8.EncodeRune(rb[:], rv)
-					// skipp already processed bytes
-					rb = shiftNRuneBytes(rb, off)
-
-					for i := 0; i < len(n.indices); i++ {
-						// lowercase matches
-						if n.indices[i] == rb[0] {
-							// must use a recursive approach since both the
-							// uppercase byte and the lowercase byte might exist
-							// as an index
-							if out, found, err := n.edges[i].findCaseInsensitiveRec(
-								path, loPath, ciPath, rb, fixTrailingSlash,
-							); found {
-								return out, found, err
-							}
-							break
-						}
-					}
-
-					// same for uppercase rune, if it differs
-					if up := unicode.ToUpper(rv); up != rv {
-						utf8.EncodeRune(rb[:], up)
-						rb = shiftNRuneBytes(rb, off)
-
-						for i := 0; i < len(n.indices); i++ {
-							// uppercase matches
-							if n.indices[i] == rb[0] {
-								// continue with edge node
-								n = n.edges[i]
-								loNPath = strings.ToLower(n.path)
-								continue walk
-							}
-						}
-					}
-				}
+	case param:
+		for _, end := range n.paramEnds(path, true) {
+			if end <= 0 {
+				continue
+			}
 
-				// Nothing found. We can recommend to redirect to the same URL
-				// without a trailing slash if a leaf exists for that path
-				return ciPath, (fixTrailingSlash && path == SlashString && n.value != nil), nil
+			if n.constraint != nil && !n.constraint.fn(path[:end]) {
+				continue
 			}
 
-			n = n.edges[0]
-			switch n.nType {
-			case param:
-				// find param end (either '/' or path end)
-				k := 0
-				for k < len(path) && path[k] != slashByte {
-					k++
+			// add param value to case insensitive path
+			out := append(ciPath, path[:end]...)
+			if end == len(path) {
+				if n.value != nil {
+					return out, true, nil
 				}
+			} else if out, found, err := n.findCaseInsensitiveEdges(path[end:], out); found || err != nil {
+				return out, found, err
+			}
+		}
 
-				// add param value to case insensitive path
-				ciPath = append(ciPath, path[:k]...)
-
-				// we need to go deeper!
-				if k < len(path) {
-					if len(n.edges) > 0 {
-						// continue with edge node
-						n = n.edges[0]
-						loNPath = strings.ToLower(n.path)
-						loPath = loPath[k:]
-						path = path[k:]
-						continue
-					}
-
-					// ... but we can't
-					if fixTrailingSlash && len(path) == k+1 {
-						return ciPath, true, nil
-					}
-					return ciPath, false, nil
-				}
+		return ciPath, false, nil
 
-				if n.value != nil {
-					return ciPath, true, nil
-				} else if fixTrailingSlash && len(n.edges) == 1 {
-					// No value found. Check if a value for this path + a
-					// trailing slash exists
-					n = n.edges[0]
-					if n.path == SlashString && n.value != nil {
-						return append(ciPath, slashByte), true, nil
-					}
-				}
-				return ciPath, false, nil
+	case catchAll:
+		if len(path) == 0 || path[0] != slashByte {
+			return ciPath, false, nil
+		}
+		if n.constraint != nil && !n.constraint.fn(path[1:]) {
+			return ciPath, false, nil
+		}
+		return append(ciPath, path...), n.value != nil, nil
+
+	default:
+		return nil, false, errInvalidNodeType
+	}
+
+	return n.findCaseInsensitiveEdges(path, ciPath)
+}
+
+func (n *node) findCaseInsensitiveEdges(path string, ciPath []byte) ([]byte, bool, error) {
+	// both the uppercase byte and the lowercase byte might exist as an
+	// index, so all the edges are tried
+	for _, edge := range n.edges {
+		if out, found, err := edge.findCaseInsensitiveRec(path, ciPath); found || err != nil {
+			return out, found, err
+		}
+	}
+
+	return ciPath, false, nil
+}
+
+// parsePathTokens parses the given route path into static, param and
+// catch-all tokens. Param name consists of letters, digits and underscore, it
+// ends at any other character, so multiple params can be used within the path
+// segment separated by static text. For e.g.: `/files/:name.:ext`.
+func parsePathTokens(path string) ([]pathToken, error) {
+	var tokens []pathToken
+	start := 0
+
+	// find wildcards (beginning with ':' or '*')
+	for i, max := 0, len(path); i < max; i++ {
+		c := path[i]
+		if c != paramByte && c != wildByte {
+			continue
+		}
+
+		// find wildcard end, param ends at non-name character and
+		// catch-all ends at '/' or path end
+		end := i + 1
+		for end < max && path[end] != slashByte &&
+			(c == wildByte || isParamNameByte(path[end])) {
+			end++
+		}
 
-			case catchAll:
-				return append(ciPath, path...), true, nil
+		// optional param
+		optional := c == paramByte && end < max && path[end] == optionalByte
+
+		// the wildcard name must not be followed by ':' and '*'
+		if end < max && (path[end] == paramByte || path[end] == wildByte) {
+			return nil, fmt.Errorf("wildcards must be separated by static text, "+
+				"has: '%s' in path '%s'", path[i:], path)
+		}
 
-			default:
-				return nil, false, errInvalidNodeType
+		// check if the wildcard has a name
+		if end-i < 2 {
+			return nil, fmt.Errorf("wildcards must be named with a non-empty name"+
+				" in path '%s'", path)
+		}
+
+		if c == paramByte { // param
+			if err := checkParamName(path, i, end); err != nil {
+				return nil, err
 			}
-		} else {
-			// We should have reached the node containing the value.
-			// Check if this node has a value registered.
-			if n.value != nil {
-				return ciPath, true, nil
+			tokens = appendStaticToken(tokens, path, start, i)
+			tokens = append(tokens, pathToken{nType: param, optional: optional, path: path[i:end], pos: i})
+			if optional {
+				end++
+			}
+		} else { // catchAll
+			if end != max {
+				return nil, fmt.Errorf("catch-all routes are only allowed at the end of"+
+					" the path in path '%s'", path)
 			}
 
-			// No value found.
-			// Try to fix the path by adding a trailing slash
-			if fixTrailingSlash {
-				for i := 0; i < len(n.indices); i++ {
-					if n.indices[i] == slashByte {
-						n = n.edges[i]
-						if (len(n.path) == 1 && n.value != nil) ||
-							(n.nType == catchAll && n.edges[0].value != nil) {
-							return append(ciPath, slashByte), true, nil
-						}
-						return ciPath, false, nil
-					}
-				}
+			// currently fixed width 1 for '/'
+			if i == 0 || path[i-1] != slashByte {
+				return nil, fmt.Errorf("no / before catch-all in path '%s'", path)
 			}
-			return ciPath, false, nil
+
+			tokens = appendStaticToken(tokens, path, start, i-1)
+			tokens = append(tokens, pathToken{nType: catchAll, path: path[i-1 : end], pos: i - 1})
 		}
+
+		start = end
+		i = end - 1
 	}
 
-	// Nothing found.
-	// Try to fix the path by adding / removing a trailing slash
-	if fixTrailingSlash {
-		if path == SlashString {
-			return ciPath, true, nil
+	tokens = appendStaticToken(tokens, path, start, len(path))
+
+	// optional params must be entire trailing path segments, for e.g.:
+	// `/reports/:year?/:month?`
+	optional := false
+	for i, t := range tokens {
+		switch {
+		case t.optional:
+			optional = true
+			if t.pos > 0 && path[t.pos-1] == slashByte &&
+				(i+1 == len(tokens) || tokens[i+1].path == SlashString) {
+				continue
+			}
+		case !optional:
+			continue
+		case t.nType == static && t.path == SlashString && i+1 < len(tokens):
+			continue
 		}
-		if len(loPath)+1 == len(loNPath) && loNPath[len(loPath)] == slashByte &&
-			loPath[1:] == loNPath[1:len(loPath)] && n.value != nil {
-			return append(ciPath, n.path...), true, nil
+		return nil, fmt.Errorf("optional params must be trailing path segments"+
+			" in path '%s'", path)
+	}
+
+	return tokens, nil
+}
+
+// optionalPathPrefix returns the path prefix before the given optional param
+// token without optional markers.
+func optionalPathPrefix(path string, t pathToken) string {
+	prefix := strings.Replace(path[:t.pos-1], string(optionalByte), "", -1)
+	if len(prefix) == 0 {
+		return SlashString
+	}
+	return prefix
+}
+
+func appendStaticToken(tokens []pathToken, path string, start, end int) []pathToken {
+	if start < end {
+		tokens = append(tokens, pathToken{nType: static, path: path[start:end], pos: start})
+	}
+	return tokens
+}
+
+func wildcardConflictError(pathSeg, fullPath string, wild *node, prefix string) error {
+	if wild.nType != catchAll {
+		pathSeg = strings.SplitN(pathSeg, SlashString, 2)[0]
+	}
+	return fmt.Errorf("'%s' in new path '%s' conflicts with existing "+
+		"wildcard '%s' in existing prefix '%s'", pathSeg, fullPath, wild.path, prefix+wild.path)
+}
+
+// longestCommonPrefix returns the length of common prefix, it does not split
+// in the middle of multi-byte rune.
+func longestCommonPrefix(a, b string) int {
+	i, max := 0, min(len(a), len(b))
+	for i < max && a[i] == b[i] {
+		i++
+	}
+	for i > 0 && ((i < len(a) && !utf8.RuneStart(a[i])) || (i < len(b) && !utf8.RuneStart(b[i]))) {
+		i--
+	}
+	return i
+}
+
+// foldPrefixLen returns the length of prefix in s under case-folding
+// otherwise -1.
+func foldPrefixLen(s, prefix string) int {
+	i := 0
+	for _, pr := range prefix {
+		if i >= len(s) {
+			return -1
+		}
+		sr, size := utf8.DecodeRuneInString(s[i:])
+		if sr != pr && !equalFoldRune(sr, pr) {
+			return -1
 		}
+		i += size
 	}
-	return ciPath, false, nil
+	return i
+}
+
+func equalFoldRune(a, b rune) bool {
+	for r := unicode.SimpleFold(a); r != a; r = unicode.SimpleFold(r) {
+		if r == b {
+			return true
+		}
+	}
+	return false
+}
+
+// checkParamName method checks the param at path[start:end] is not the only
+// param of the path segment followed by static text, for e.g.: `/:user-id`,
+// `/:id.json`. Such a param was named till the end of the path segment, so it
+// is reported instead of silently becoming param followed by static text.
+func checkParamName(path string, start, end int) error {
+	if end == len(path) || path[end] == slashByte || path[end] == optionalByte {
+		return nil
+	}
+
+	segStart := strings.LastIndexByte(path[:start], slashByte) + 1
+	segEnd := end + strings.IndexByte(path[end:], slashByte)
+	if segEnd < end {
+		segEnd = len(path)
+	}
+	if strings.ContainsAny(path[segStart:start], ":*") ||
+		strings.ContainsAny(path[end:segEnd], ":*") {
+		return nil
+	}
+
+	return fmt.Errorf("param name '%s' has invalid character in path '%s', "+
+		"name may have only letters, digits and '_'", path[start:segEnd], path)
+}
+
+func isParamNameByte(c byte) bool {
+	return c == '_' || ('0' <= c && c <= '9') || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
 }
 
 func min(a, b int) int {
@@ -13,6 +13,7 @@ import (
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	ahttp "aahframework.org/ahttp.v0"
 	"aahframework.org/test.v0/assert"
@@ -107,13 +108,13 @@ func TestTreeWildcard(t *testing.T) {
 	checkRequests(t, tree, testRequests{
 		{"/", false, "/", nil},
 		{"/cmd/test/", false, "/cmd/:tool/", ahttp.PathParams{"tool": "test"}},
-		{"/cmd/test", true, "", ahttp.PathParams{"tool": "test"}},
+		{"/cmd/test", true, "", nil},
 		{"/cmd/test/3", false, "/cmd/:tool/:sub", ahttp.PathParams{"tool": "test", "sub": "3"}},
 		{"/src/", false, "/src/*filepath", ahttp.PathParams{"filepath": "/"}},
 		{"/src/some/file.png", false, "/src/*filepath", ahttp.PathParams{"filepath": "/some/file.png"}},
 		{"/search/", false, "/search/", nil},
 		{"/search/someth!ng+in+ünìcodé", false, "/search/:query", ahttp.PathParams{"query": "someth!ng+in+ünìcodé"}},
-		{"/search/someth!ng+in+ünìcodé/", true, "", ahttp.PathParams{"query": "someth!ng+in+ünìcodé"}},
+		{"/search/someth!ng+in+ünìcodé/", true, "", nil},
 		{"/user_gopher", false, "/user_:name", ahttp.PathParams{"name": "gopher"}},
 		{"/user_gopher/about", false, "/user_:name/about", ahttp.PathParams{"name": "gopher"}},
 		{"/files/js/inc/framework.js", false, "/files/:dir/*filepath", ahttp.PathParams{"dir": "js", "filepath": "/inc/framework.js"}},
@@ -125,6 +126,103 @@ func TestTreeWildcard(t *testing.T) {
 	checkMaxParams(t, tree)
 }
 
+func TestTreeParamConstraints(t *testing.T) {
+	tree := &node{}
+
+	intConstraint, _ := compileConstraint("int")
+	slugConstraint, _ := compileConstraint("slug")
+
+	routes := []struct {
+		path        string
+		constraints map[string]*constraint
+	}{
+		{"/items/:slug", map[string]*constraint{"slug": slugConstraint}},
+		{"/items/:id", map[string]*constraint{"id": intConstraint}},
+		{"/items/:id/edit", map[string]*constraint{"id": intConstraint}},
+		{"/items/:slug/view", map[string]*constraint{"slug": slugConstraint}},
+		{"/items/:name", nil},
+		{"/items/:name/*filepath", nil},
+	}
+	for _, route := range routes {
+		err := tree.insert(route.path, route.path, route.constraints)
+		assert.FailNowOnErrorf(t, err, "error inserting route '%s': %v", route.path, err)
+	}
+
+	//printChildren(tree, "")
+
+	checkRequests(t, tree, testRequests{
+		{"/items/123", false, "/items/:id", ahttp.PathParams{"id": "123"}},
+		{"/items/hello-world", false, "/items/:slug", ahttp.PathParams{"slug": "hello-world"}},
+		{"/items/Hello_World", false, "/items/:name", ahttp.PathParams{"name": "Hello_World"}},
+		{"/items/123/edit", false, "/items/:id/edit", ahttp.PathParams{"id": "123"}},
+		{"/items/123/view", false, "/items/:slug/view", ahttp.PathParams{"slug": "123"}},
+		{"/items/hello-world/edit", false, "/items/:name/*filepath", ahttp.PathParams{"name": "hello-world", "filepath": "/edit"}},
+		{"/items/Hello/view", false, "/items/:name/*filepath", ahttp.PathParams{"name": "Hello", "filepath": "/view"}},
+	})
+
+	checkPriorities(t, tree)
+	checkMaxParams(t, tree)
+
+	// path params without constraints
+	value, pp, err := tree.lookup("/items/hello-world/edit", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, "/items/:id/edit", value)
+	assert.Equal(t, ahttp.PathParams{"id": "hello-world"}, pp)
+
+	// only one param without constraint is allowed
+	err = tree.add("/items/:other", nil)
+	assert.Equal(t, "':other' in new path '/items/:other' conflicts with existing wildcard ':name' in existing prefix '/items/:name'", err.Error())
+
+	// same param and constraint shares the edge
+	err = tree.insert("/items/:id/delete", "/items/:id/delete", map[string]*constraint{"id": intConstraint})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(tree.edges[0].edges))
+}
+
+func TestTreeStaticWildcardPrecedence(t *testing.T) {
+	tree := &node{}
+
+	routes := [...]string{
+		"/users/:id",
+		"/users/new",
+		"/users/:id/edit",
+		"/users/new/profile",
+		"/files/*filepath",
+		"/files/favicon.ico",
+		"/files/",
+		"/docs/:page",
+		"/docs/*filepath",
+		"/*filepath",
+		"/",
+	}
+	for _, route := range routes {
+		err := tree.add(route, route)
+		assert.FailNowOnErrorf(t, err, "error inserting route '%s': %v", route, err)
+	}
+
+	//printChildren(tree, "")
+
+	checkRequests(t, tree, testRequests{
+		{"/users/new", false, "/users/new", nil},
+		{"/users/123", false, "/users/:id", ahttp.PathParams{"id": "123"}},
+		{"/users/newest", false, "/users/:id", ahttp.PathParams{"id": "newest"}},
+		{"/users/ne", false, "/users/:id", ahttp.PathParams{"id": "ne"}},
+		{"/users/new/edit", false, "/users/:id/edit", ahttp.PathParams{"id": "new"}},
+		{"/users/new/profile", false, "/users/new/profile", nil},
+		{"/users/123/profile", false, "/*filepath", ahttp.PathParams{"filepath": "/users/123/profile"}},
+		{"/files/favicon.ico", false, "/files/favicon.ico", nil},
+		{"/files/", false, "/files/", nil},
+		{"/files/css/app.css", false, "/files/*filepath", ahttp.PathParams{"filepath": "/css/app.css"}},
+		{"/docs/intro", false, "/docs/:page", ahttp.PathParams{"page": "intro"}},
+		{"/docs/guide/intro", false, "/docs/*filepath", ahttp.PathParams{"filepath": "/guide/intro"}},
+		{"/", false, "/", nil},
+		{"/about", false, "/*filepath", ahttp.PathParams{"filepath": "/about"}},
+	})
+
+	checkPriorities(t, tree)
+	checkMaxParams(t, tree)
+}
+
 func testRoutes(t *testing.T, routes []testRoute) {
 	tree := &node{}
 
@@ -143,20 +241,16 @@ func testRoutes(t *testing.T, routes []testRoute) {
 func TestTreeWildcardConflict(t *testing.T) {
 	routes := []testRoute{
 		{"/cmd/:tool/:sub", false},
-		{"/cmd/vet", true},
+		{"/cmd/:tool/:subx", true},
+		{"/cmd/:toolx", true},
 		{"/src/*filepath", false},
 		{"/src/*filepathx", true},
-		{"/src/", true},
-		{"/src1/", false},
-		{"/src1/*filepath", true},
 		{"/src2*filepath", true},
 		{"/search/:query", false},
-		{"/search/invalid", true},
+		{"/search/:term", true},
 		{"/user_:name", false},
-		{"/user_x", true},
 		{"/user_:name", false},
-		{"/id:id", false},
-		{"/id/:id", true},
+		{"/user_:id", true},
 	}
 	testRoutes(t, routes)
 }
@@ -164,15 +258,15 @@ func TestTreeWildcardConflict(t *testing.T) {
 func TestTreeChildConflict(t *testing.T) {
 	routes := []testRoute{
 		{"/cmd/vet", false},
-		{"/cmd/:tool/:sub", true},
+		{"/cmd/:tool/:sub", false},
 		{"/src/AUTHORS", false},
-		{"/src/*filepath", true},
+		{"/src/*filepath", false},
 		{"/user_x", false},
-		{"/user_:name", true},
+		{"/user_:name", false},
 		{"/id/:id", false},
-		{"/id:id", true},
-		{"/:id", true},
-		{"/*filepath", true},
+		{"/id:id", false},
+		{"/:id", false},
+		{"/*filepath", false},
 	}
 	testRoutes(t, routes)
 }
@@ -234,13 +328,14 @@ func TestTreeCatchAllConflict(t *testing.T) {
 func TestTreeCatchAllConflictRoot(t *testing.T) {
 	routes := []testRoute{
 		{"/", false},
-		{"/*filepath", true},
+		{"/*filepath", false},
+		{"/*other", true},
 	}
 	testRoutes(t, routes)
 }
 
 func TestTreeDoubleWildcard(t *testing.T) {
-	const panicMsg = "only one wildcard per path segment is allowed"
+	const panicMsg = "wildcards must be separated by static text"
 
 	routes := [...]string{
 		"/:foo:bar",
@@ -257,6 +352,122 @@ func TestTreeDoubleWildcard(t *testing.T) {
 	}
 }
 
+func TestTreeMultiParamSegment(t *testing.T) {
+	tree := &node{}
+
+	routes := [...]string{
+		"/files/:name.:ext",
+		"/files/:name",
+		"/v:major.:minor/docs",
+		"/archive/:year-:month",
+		"/archive/:year-:month/:day",
+	}
+	for _, route := range routes {
+		err := tree.add(route, route)
+		assert.FailNowOnErrorf(t, err, "error inserting route '%s': %v", route, err)
+	}
+
+	//printChildren(tree, "")
+
+	checkRequests(t, tree, testRequests{
+		{"/files/report.pdf", false, "/files/:name.:ext", ahttp.PathParams{"name": "report", "ext": "pdf"}},
+		{"/files/archive.tar.gz", false, "/files/:name.:ext", ahttp.PathParams{"name": "archive.tar", "ext": "gz"}},
+		{"/files/README", false, "/files/:name", ahttp.PathParams{"name": "README"}},
+		{"/files/report.", false, "/files/:name", ahttp.PathParams{"name": "report."}},
+		{"/v1.2/docs", false, "/v:major.:minor/docs", ahttp.PathParams{"major": "1", "minor": "2"}},
+		{"/v1/docs", true, "", nil},
+		{"/archive/2017-10", false, "/archive/:year-:month", ahttp.PathParams{"year": "2017", "month": "10"}},
+		{"/archive/2017-10/31", false, "/archive/:year-:month/:day", ahttp.PathParams{"year": "2017", "month": "10", "day": "31"}},
+		{"/archive/2017", true, "", nil},
+	})
+
+	checkPriorities(t, tree)
+	checkMaxParams(t, tree)
+
+	out, found, err := tree.findCaseInsensitive("/V1.2/DOCS", false)
+	assert.Nil(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "/v1.2/docs", out)
+
+	// params must be separated by static text
+	err = tree.add("/archive/:year:month", nil)
+	assert.Equal(t, "wildcards must be separated by static text, has: ':year:month' in path '/archive/:year:month'", err.Error())
+
+	// param name is not cut short at non-name character, when it is the only
+	// param in the path segment
+	for _, route := range []string{"/users/:user-id", "/files/:name.json", "/v:major.x/docs"} {
+		err = tree.add(route, nil)
+		assert.NotNilf(t, err, "route: %s", route)
+		assert.Truef(t, strings.HasPrefix(err.Error(), "param name '"), "got: %v", err)
+	}
+	err = tree.add("/users/:user-id/profile", nil)
+	assert.Equal(t, "param name ':user-id' has invalid character in path '/users/:user-id/profile', name may have only letters, digits and '_'", err.Error())
+}
+
+func TestTreeMultiParamSegmentLongPath(t *testing.T) {
+	tree := &node{}
+	for _, route := range []string{"/v:major.:minor/docs", "/r:major.:minor.:patch/docs"} {
+		assert.Nil(t, tree.add(route, route))
+	}
+
+	checkRequests(t, tree, testRequests{
+		{"/r1.2.3/docs", false, "/r:major.:minor.:patch/docs", ahttp.PathParams{"major": "1", "minor": "2", "patch": "3"}},
+		{"/r1.2.3.4/docs", false, "/r:major.:minor.:patch/docs", ahttp.PathParams{"major": "1", "minor": "2.3", "patch": "4"}},
+	})
+
+	// lookup cost is linear to the path length
+	start := time.Now()
+	for _, prefix := range []string{"/v", "/r"} {
+		path := prefix + strings.Repeat(".", 8192) + "/nope"
+		value, _, _, err := tree.find(path, 0)
+		assert.Nil(t, err)
+		assert.Nil(t, value)
+		_, found, err := tree.findCaseInsensitive(path, true)
+		assert.Nil(t, err)
+		assert.False(t, found)
+	}
+	assert.Truef(t, time.Since(start) < time.Second, "lookup took %s", time.Since(start))
+}
+
+func TestTreeOptionalSegments(t *testing.T) {
+	tree := &node{}
+
+	routes := [...]string{
+		"/reports/:period?",
+		"/archive/:year?/:month?",
+		"/:lang?",
+	}
+	for _, route := range routes {
+		err := tree.add(route, route)
+		assert.FailNowOnErrorf(t, err, "error inserting route '%s': %v", route, err)
+	}
+
+	//printChildren(tree, "")
+
+	checkRequests(t, tree, testRequests{
+		{"/reports", false, "/reports/:period?", nil},
+		{"/reports/week", false, "/reports/:period?", ahttp.PathParams{"period": "week"}},
+		{"/archive", false, "/archive/:year?/:month?", nil},
+		{"/archive/2017", false, "/archive/:year?/:month?", ahttp.PathParams{"year": "2017"}},
+		{"/archive/2017/10", false, "/archive/:year?/:month?", ahttp.PathParams{"year": "2017", "month": "10"}},
+		{"/", false, "/:lang?", nil},
+		{"/en", false, "/:lang?", ahttp.PathParams{"lang": "en"}},
+	})
+
+	checkPriorities(t, tree)
+	checkMaxParams(t, tree)
+
+	for _, route := range [...]string{
+		"/users/:id?/edit",
+		"/users/:id?/:action",
+		"/users/:id?/",
+		"/users/v:id?",
+	} {
+		err := tree.add(route, route)
+		assert.Equal(t, "optional params must be trailing path segments in path '"+route+"'", err.Error())
+	}
+}
+
 func TestTreeTrailingSlashRedirect(t *testing.T) {
 	tree := &node{}
 
@@ -310,7 +521,7 @@ func TestTreeTrailingSlashRedirect(t *testing.T) {
 		"/doc/",
 	}
 	for _, route := range tsrRoutes {
-		handler, _, tsr, _ := tree.find(route)
+		handler, _, tsr, _ := tree.find(route, 0)
 		if handler != nil {
 			t.Fatalf("non-nil handler for TSR route '%s", route)
 		} else if !tsr {
@@ -328,7 +539,7 @@ func TestTreeTrailingSlashRedirect(t *testing.T) {
 	}
 
 	for _, route := range noTsrRoutes {
-		handler, _, tsr, _ := tree.find(route)
+		handler, _, tsr, _ := tree.find(route, 0)
 		if handler != nil {
 			t.Fatalf("non-nil handler for No-TSR route '%s", route)
 		} else if tsr {
@@ -343,7 +554,7 @@ func TestTreeRootTrailingSlashRedirect(t *testing.T) {
 	err := tree.add("/:test", "/:test")
 	assert.FailNowOnError(t, err, "error inserting test route")
 
-	handler, _, tsr, _ := tree.find("/")
+	handler, _, tsr, _ := tree.find("/", 0)
 	if handler != nil {
 		t.Fatalf("non-nil handler")
 	} else if tsr {
@@ -518,7 +729,7 @@ func TestTreeInvalidNodeType(t *testing.T) {
 	tree.edges[0].nType = 42
 
 	// normal lookup
-	_, _, _, err := tree.find("/test")
+	_, _, _, err := tree.find("/test", 0)
 	assert.Equal(t, panicMsg, err.Error())
 
 	_, _, err = tree.findCaseInsensitive("/test", true)
@@ -532,11 +743,9 @@ func TestTreeWildcardConflictEx(t *testing.T) {
 		existPath    string
 		existSegPath string
 	}{
-		{"/who/are/foo", "/foo", `/who/are/\*you`, `/\*you`},
-		{"/who/are/foo/", "/foo/", `/who/are/\*you`, `/\*you`},
-		{"/who/are/foo/bar", "/foo/bar", `/who/are/\*you`, `/\*you`},
-		{"/conxxx", "xxx", `/con:tact`, `:tact`},
-		{"/conooo/xxx", "ooo", `/con:tact`, `:tact`},
+		{"/who/are/*me", `/\*me`, `/who/are/\*you`, `/\*you`},
+		{"/con:name", ":name", `/con:tact`, `:tact`},
+		{"/con:name/xxx", ":name", `/con:tact`, `:tact`},
 	}
 
 	for _, conflict := range conflicts {
@@ -564,7 +773,7 @@ func TestTreeWildcardConflictEx(t *testing.T) {
 
 func checkRequests(t *testing.T, tree *node, requests testRequests) {
 	for _, request := range requests {
-		handler, pp, _, _ := tree.find(request.path)
+		handler, pp, _, _ := tree.find(request.path, 0)
 
 		if handler == nil {
 			assert.Truef(t, request.nilHandler, "value mismatch for route '%s': Expected non-nil value", request.path)
@@ -608,7 +817,7 @@ func checkMaxParams(t *testing.T, n *node) uint8 {
 			maxParams = params
 		}
 	}
-	if n.nType > root && !n.wildChild {
+	if n.nType == param || n.nType == catchAll {
 		maxParams++
 	}
 
@@ -620,14 +829,13 @@ func checkMaxParams(t *testing.T, n *node) uint8 {
 }
 
 func printChildren(n *node, prefix string) {
-	fmt.Printf(" %02d:%02d %s%s[%d] %v %t %d \r\n",
+	fmt.Printf(" %02d:%02d %s%s[%d] %v %d \r\n",
 		n.priority,
 		n.maxParams,
 		prefix,
 		n.path,
 		len(n.edges),
 		n.value,
-		n.wildChild,
 		n.nType)
 
 	for l := len(n.path); l > 0; l-- {
@@ -8,7 +8,9 @@ import (
 	"bytes"
 	"fmt"
 	"strings"
+	"time"
 
+	"aahframework.org/ahttp.v0"
 	"aahframework.org/config.v0"
 	"aahframework.org/security.v0"
 	"aahframework.org/security.v0/authz"
@@ -35,10 +37,36 @@ type Route struct {
 	File            string
 	CORS            *CORS
 	Constraints     map[string]string
-
+	Defaults        map[string]string
+	Meta            map[string]interface{}
+	Interceptors    []string
+	Description     string
+	Tags            []string
+	Owner           string
+	Deprecated      bool
+	Sunset          time.Time
+
+	constraints       map[string]*constraint
 	authorizationInfo *authorizationInfo
 }
 
+// PathToken holds the parsed portion of the route path, it is either static
+// text or path param. Catch-all param is preceded by static text '/'.
+type PathToken struct {
+	Text  string
+	Param *PathParam
+}
+
+// PathParam holds the route path param details, constraint expression is as
+// configured and it's rules are comma separated values of the expression.
+type PathParam struct {
+	Name            string
+	CatchAll        bool
+	Optional        bool
+	Constraint      string
+	ConstraintRules []string
+}
+
 // IsDir method returns true if serving directory otherwise false.
 func (r *Route) IsDir() bool {
 	return len(r.Dir) > 0 && len(r.File) == 0
@@ -121,6 +149,55 @@ func (r *Route) HasAccess(subject *security.Subject) (bool, []*authz.Reason) {
 	return rolesResult && permissionResult, reasons
 }
 
+// IsAutoAdded method returns true if the route is added by router, for e.g.:
+// form auth login submit route per 'security.conf'.
+func (r *Route) IsAutoAdded() bool {
+	return strings.HasSuffix(r.Name, autoRouteNameSuffix)
+}
+
+// PathTokens method returns the parsed route path, for e.g.: `/users/:id`
+// becomes static text `/users/` and path param `id`. It returns nil if route
+// path is not valid.
+func (r *Route) PathTokens() []PathToken {
+	tokens, err := parsePathTokens(r.Path)
+	if err != nil {
+		return nil
+	}
+
+	pathTokens := make([]PathToken, 0, len(tokens)+1)
+	for _, t := range tokens {
+		if t.nType == static {
+			pathTokens = append(pathTokens, PathToken{Text: t.path})
+			continue
+		}
+
+		param := &PathParam{Optional: t.optional, CatchAll: t.nType == catchAll}
+		if param.CatchAll {
+			pathTokens = append(pathTokens, PathToken{Text: SlashString})
+			param.Name = t.path[2:]
+		} else {
+			param.Name = t.path[1:]
+		}
+		if expr, found := r.Constraints[param.Name]; found {
+			param.Constraint = expr
+			param.ConstraintRules = splitConstraintRules(expr)
+		}
+		pathTokens = append(pathTokens, PathToken{Param: param})
+	}
+	return pathTokens
+}
+
+// PathParams method returns the route path params in the order of path.
+func (r *Route) PathParams() []*PathParam {
+	var params []*PathParam
+	for _, t := range r.PathTokens() {
+		if t.Param != nil {
+			params = append(params, t.Param)
+		}
+	}
+	return params
+}
+
 // String method is Stringer interface.
 func (r *Route) String() string {
 	if r.IsStatic {
@@ -138,9 +215,122 @@ func (r *Route) String() string {
 // Unexported types and methods
 //______________________________________________________________________________
 
+// isSameDefinition method returns true if the given route is from the same
+// route definition, i.e. route configured with multiple HTTP methods.
+func (r *Route) isSameDefinition(o *Route) bool {
+	return r.Name == o.Name && r.Path == o.Path && r.Target == o.Target &&
+		r.Action == o.Action && r.ParentName == o.ParentName && r.IsStatic == o.IsStatic
+}
+
+// definition method returns the short description of route definition, used
+// in the error messages.
+func (r *Route) definition() string {
+	var def string
+	if r.IsStatic {
+		def = fmt.Sprintf("static %s", r.Path)
+	} else {
+		def = fmt.Sprintf("%s %s => %s.%s", r.Method, r.Path, r.Target, r.Action)
+	}
+	if len(r.ParentName) > 0 {
+		def += ", parent " + r.ParentName
+	}
+	return def
+}
+
+// newRoutes method returns the route for each HTTP method of the given route
+// definition, HTTP methods are comma separated in the `Method`. It is used by
+// both routes config and route group. Max body size is applicable only to
+// HTTP methods POST, PUT and DELETE; anti-CSRF check, CORS and max body size
+// are not applicable to WebSocket.
+func newRoutes(def Route) []*Route {
+	if def.Method == methodWebSocket {
+		def.IsAntiCSRFCheck = false
+		def.CORS = nil
+		def.MaxBodySize = 0
+	} else if !payloadSupported.MatchString(def.Method) {
+		def.MaxBodySize = 0
+	}
+
+	methods := strings.Split(def.Method, ",")
+	routes := make([]*Route, 0, len(methods))
+	for _, m := range methods {
+		route := def
+		route.Method = strings.TrimSpace(m)
+		routes = append(routes, &route)
+	}
+	return routes
+}
+
+// compileConstraints method compiles the route path parameter constraints
+// into constraint funcs.
+func (r *Route) compileConstraints() error {
+	if len(r.Constraints) == 0 {
+		return nil
+	}
+
+	r.constraints = make(map[string]*constraint, len(r.Constraints))
+	for paramName, expr := range r.Constraints {
+		c, err := compileConstraint(expr)
+		if err != nil {
+			return fmt.Errorf("'%s.path' has %s on param '%s' in path => '%s'", r.Name, err, paramName, r.Path)
+		}
+		r.constraints[paramName] = c
+	}
+
+	return nil
+}
+
+// validateDefaults method validates the route defaults, default is applicable
+// only to optional path param and it has to satisfy the param constraint.
+func (r *Route) validateDefaults() error {
+	if len(r.Defaults) == 0 {
+		return nil
+	}
+
+	tokens, err := parsePathTokens(r.Path)
+	if err != nil {
+		return err
+	}
+
+	for paramName, value := range r.Defaults {
+		optional := false
+		for _, t := range tokens {
+			if t.optional && t.path[1:] == paramName {
+				optional = true
+				break
+			}
+		}
+		if !optional {
+			return fmt.Errorf("'%s.defaults' has '%s' which is not an optional param in path => '%s'", r.Name, paramName, r.Path)
+		}
+
+		if c, found := r.constraints[paramName]; found && !c.fn(value) {
+			return fmt.Errorf("'%s.defaults' value '%s' does not satisfy constraint '%s' on param '%s' in path => '%s'", r.Name, value, c.expr, paramName, r.Path)
+		}
+	}
+
+	return nil
+}
+
+// addDefaults method adds the route defaults into given path params for
+// absent optional path segments.
+func (r *Route) addDefaults(pathParams ahttp.PathParams) ahttp.PathParams {
+	for paramName, value := range r.Defaults {
+		if _, found := pathParams[paramName]; found {
+			continue
+		}
+		if pathParams == nil {
+			pathParams = make(ahttp.PathParams, len(r.Defaults))
+		}
+		pathParams[paramName] = value
+	}
+	return pathParams
+}
+
 type parentRouteInfo struct {
 	AntiCSRFCheck     bool
 	CORSEnabled       bool
+	NamespaceNames    bool
 	ParentName        string
 	PrefixPath        string
 	Target            string
@@ -148,6 +338,12 @@ type parentRouteInfo struct {
 	MaxBodySizeStr    string
 	CORS              *CORS
 	AuthorizationInfo *authorizationInfo
+	Meta              map[string]interface{}
+	Interceptors      []string
+	Tags              []string
+	Owner             string
+	Deprecated        bool
+	Sunset            time.Time
 }
 
 type authorizationInfo struct {
@@ -160,6 +356,12 @@ func (a *authorizationInfo) SatisfyEither() bool {
 	return a.Satisfy == "either"
 }
 
+// isSatisfiable method returns false if satisfy is `both`, however roles or
+// permissions is not configured.
+func (a *authorizationInfo) isSatisfiable() bool {
+	return a.Satisfy != "both" || (len(a.Roles) > 0 && len(a.Permissions) > 0)
+}
+
 func (a *authorizationInfo) String() string {
 	buf := new(bytes.Buffer)
 	buf.WriteString("authorizationinfo(satisfy:")
@@ -222,7 +424,7 @@ func parseAuthorizationInfo(cfg *config.Config, routeName string, parentRoute *p
 	}
 
 	// Check statisfy
-	if info.Satisfy == "both" && (len(info.Roles) == 0 || len(info.Permissions) == 0) {
+	if !info.isSatisfiable() {
 		return nil, fmt.Errorf("%v.authorization.satisfy configured as 'both', however roles and permissions is not configured",
 			routeName)
 	}
@@ -16,6 +16,7 @@ import (
 	"path"
 	"regexp"
 	"strings"
+	"time"
 
 	"aahframework.org/ahttp.v0"
 	"aahframework.org/config.v0"
@@ -27,11 +28,18 @@ import (
 )
 
 const (
-	wildcardSubdomainPrefix = "*."
-	methodWebSocket         = "WS"
-	autoRouteNameSuffix     = "__aah"
+	methodWebSocket     = "WS"
+	autoRouteNameSuffix = "__aah"
+	schemeHTTP          = "http"
+	schemeHTTPS         = "https"
 )
 
+// SubdomainArg is the argument name to supply the subdomain value for wildcard
+// domain on absolute URL generation. For e.g.: domain host is `*.sample.com`
+// and argument `_subdomain` value is `tenant1` then URL host is
+// `tenant1.sample.com`.
+const SubdomainArg = "_subdomain"
+
 var (
 	// HTTPMethodActionMap is default Controller Action name for corresponding
 	// HTTP Method. If it's not provided in the route configuration.
@@ -52,6 +60,25 @@ var (
 
 	// ErrRouteConstraintFailed returned when request route constraints failed.
 	ErrRouteConstraintFailed = errors.New("router: route constraints failed")
+
+	// ErrDomainNotFound returned by absolute URL generation when domain not
+	// found for given host.
+	ErrDomainNotFound = errors.New("router: domain not found")
+
+	// ErrRouteNotFound returned by reverse routing when route name not found.
+	ErrRouteNotFound = errors.New("router: route not found")
+
+	// ErrMissingParam returned by reverse routing when route path param value
+	// is not supplied.
+	ErrMissingParam = errors.New("router: missing route param value")
+
+	// ErrTooManyParams returned by reverse routing when supplied values are more
+	// than route path params.
+	ErrTooManyParams = errors.New("router: too many route param values")
+
+	// ErrConstraintViolation returned by reverse routing when supplied value is
+	// not valid for the route path param.
+	ErrConstraintViolation = errors.New("router: route param value violates constraint")
 )
 
 // aah application interface for minimal purpose
@@ -111,9 +138,11 @@ type Router struct {
 
 	configPath string
 	rootDomain *Domain
+	index      *hostIndex
 	app        application
 	config     *config.Config
 	aCfg       *config.Config // kept for backward purpose, to be removed in subsequent release
+	err        error
 }
 
 // Load method loads a configuration from given file e.g. `routes.conf` and
@@ -147,25 +176,76 @@ func (r *Router) FindDomain(req *ahttp.Request) *Domain {
 	return r.Lookup(req.Host)
 }
 
-// Lookup method returns domain for given host otherwise nil.
+// Lookup method returns domain for given host otherwise nil. Host is matched
+// against the domain host patterns from most to least specific, for e.g.:
+//
+//	sample.com       exact host
+//	api-*.sample.com label prefix, matches `api-eu.sample.com`
+//	:tenant.app.com  host param, matches `tenant1.app.com`
+//	api.*.sample.com wildcard label, matches `api.eu.sample.com`
+//	*.sample.com     leftmost wildcard, matches `a.sample.com`, `a.b.sample.com`
+//
+// Host is normalized before the match, default ports `80` and `443`, trailing
+// dot are removed, IPv6 address is matched in canonical form and
+// internationalized hostname is matched in punycode. Port of the host should
+// match the domain port unless domain has `any_port` enabled.
+//
+// Domain aliases are matched as exact hosts. If host does not match any
+// domain then domain with `default = true` is returned. Exact hosts and
+// aliases are found in constant time from the host index built on `Load`.
 func (r *Router) Lookup(host string) *Domain {
+	domain, _ := r.LookupHost(host)
+	return domain
+}
+
+// LookupHost method returns domain for given host same as `Lookup` and the
+// canonical host redirect indicator. It is true if the host is domain alias
+// and domain has `canonical_redirect` enabled, then request should be
+// redirected to `Domain.CanonicalHost` for e.g.: with HTTP status 301.
+func (r *Router) LookupHost(host string) (*Domain, bool) {
+	if len(r.Domains) == 1 && len(r.Domains[0].Aliases) == 0 {
+		return r.Domains[0], false // only one domain scenario
+	}
+
+	if r.index == nil {
+		return nil, false
+	}
+
+	hostname, port := normalizeHost(host)
+	if e, _ := r.index.lookup(hostname, port, false); e != nil {
+		return e.domain, e.alias && e.domain.CanonicalRedirect
+	}
+
 	if len(r.Domains) == 1 {
-		return r.Domains[0] // only one domain scenario
+		return r.Domains[0], false
+	}
+	if r.index.def != nil {
+		return r.index.def, false
 	}
+	return r.defaultDomain(), false
+}
 
-	// Extact match of host value
-	// for e.g.: sample.com:8080, www.sample.com:8080, admin.sample.com:8080
-	if domain := r.findDomain(host); domain != nil {
-		return domain
+// AddDomainAlias method adds the given alias hosts for the domain, alias is
+// an exact host and it is matched with the domain port.
+func (r *Router) AddDomainAlias(domain *Domain, aliases ...string) error {
+	if len(domain.hostParams) > 0 {
+		return fmt.Errorf("router: domain '%s' has host params, aliases are not supported", domain.Name)
 	}
 
-	// Wildcard match of host value
-	// for e.g.: router.conf value is `*.sample.com:8080` it matches
-	// {subdomain}.sample.com
-	if idx := strings.IndexByte(host, '.'); idx > 0 {
-		return r.findDomain(wildcardSubdomainPrefix + host[idx+1:])
+	if r.index == nil || r.index.keys[domain.Key] != domain {
+		return fmt.Errorf("router: domain '%s' is not added into router", domain.Name)
 	}
 
+	for _, alias := range aliases {
+		alias = strings.TrimSpace(alias)
+		if len(alias) == 0 || isHostPattern(alias) {
+			return fmt.Errorf("router: domain '%s' alias '%s' is invalid, it should be an exact host", domain.Name, alias)
+		}
+		if err := r.index.addAlias(alias, domain); err != nil {
+			return fmt.Errorf("router: domain '%s' alias %s", domain.Name, err)
+		}
+		domain.Aliases = append(domain.Aliases, alias)
+	}
 	return nil
 }
 
@@ -176,6 +256,12 @@ func (r *Router) RootDomain() *Domain {
 	return r.rootDomain
 }
 
+// Err method returns the first error occurred on adding the domain using
+// method `AddDomain` otherwise nil.
+func (r *Router) Err() error {
+	return r.err
+}
+
 // DomainAddresses method returns domain addresses (host:port) from
 // routes configuration.
 func (r *Router) DomainAddresses() []string {
@@ -192,8 +278,7 @@ func (r *Router) RegisteredActions() map[string]map[string]uint8 {
 	methods := map[string]map[string]uint8{}
 	for _, d := range r.Domains {
 		for _, route := range d.routes {
-			if route.IsStatic || route.Method == methodWebSocket ||
-				strings.HasSuffix(route.Name, autoRouteNameSuffix) {
+			if route.IsStatic || route.Method == methodWebSocket || route.IsAutoAdded() {
 				continue
 			}
 			addRegisteredAction(methods, route)
@@ -216,12 +301,128 @@ func (r *Router) RegisteredWSActions() map[string]map[string]uint8 {
 	return methods
 }
 
+// AbsoluteURL method composes absolute reverse URL for given domain host, route
+// name and arguments. URL scheme, host and port are taken from the domain
+// configuration. For wildcard domain, subdomain value is taken from given host
+// for e.g.: `tenant1.sample.com` or from argument `SubdomainArg` if the host is
+// wildcard for e.g.: `*.sample.com`. Similarly host param values are taken
+// from given host or from arguments by param name if the host is pattern for
+// e.g.: `:tenant.app.com`.
+//
+//	router.AbsoluteURL("admin.sample.com", "dashboard", nil)
+//	router.AbsoluteURL("*.sample.com", "home", map[string]interface{}{
+//		router.SubdomainArg: "tenant1",
+//	})
+//	router.AbsoluteURL(":tenant.app.com", "home", map[string]interface{}{
+//		"tenant": "tenant1",
+//	})
+//
+// It logs the error and returns empty string if unable to compose the URL,
+// use method `BuildAbsoluteURL` to get the error.
+func (r *Router) AbsoluteURL(host, routeName string, args map[string]interface{}) string {
+	absURL, err := r.BuildAbsoluteURL(host, routeName, args)
+	if err != nil {
+		log.Error(err)
+		return ""
+	}
+	return absURL
+}
+
+// BuildAbsoluteURL method composes absolute reverse URL same as `AbsoluteURL`.
+// It returns `*RouteURLError` on error, which holds one of the errors
+// `ErrDomainNotFound`, `ErrRouteNotFound`, `ErrMissingParam` and
+// `ErrConstraintViolation`.
+func (r *Router) BuildAbsoluteURL(host, routeName string, args map[string]interface{}) (string, error) {
+	domain, hostname := r.findDomainByHost(host)
+	if domain == nil {
+		return "", &RouteURLError{Route: routeName, Err: ErrDomainNotFound,
+			Reason: fmt.Sprintf("host '%s'", host)}
+	}
+
+	urlHost := hostname
+	if len(urlHost) == 0 {
+		var err error
+		if urlHost, err = domain.buildHost(routeName, args); err != nil {
+			return "", err
+		}
+	}
+
+	// host arguments are not part of route path or query string
+	args = domain.routeArgs(args)
+
+	routePath, err := domain.BuildURL(routeName, args)
+	if err != nil {
+		return "", err
+	}
+
+	if len(domain.Port) > 0 {
+		urlHost += ":" + domain.Port
+	}
+
+	urlScheme := domain.Scheme
+	if len(urlScheme) == 0 {
+		urlScheme = schemeHTTP
+	}
+
+	return urlScheme + "://" + strings.ToLower(urlHost) + routePath, nil
+}
+
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
 // Router unexpoted methods
 //______________________________________________________________________________
 
 func (r *Router) findDomain(key string) *Domain {
 	key = strings.ToLower(key)
+	if r.index != nil {
+		return r.index.keys[key]
+	}
+	return r.scanDomains(key)
+}
+
+// findDomainByHost method returns the domain for given host, host could be
+// with or without port. If host matches the domain host pattern then hostname
+// of the host is returned too.
+func (r *Router) findDomainByHost(host string) (*Domain, string) {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if r.index == nil {
+		return r.scanDomains(host), ""
+	}
+	if d, found := r.index.keys[host]; found {
+		return d, ""
+	}
+	if d, found := r.index.names[host]; found {
+		return d, ""
+	}
+
+	if strings.IndexByte(host, wildByte) >= 0 {
+		return nil, ""
+	}
+
+	hostname, port := normalizeHost(host)
+	if e, _ := r.index.lookup(hostname, port, len(port) == 0); e != nil {
+		if e.alias {
+			// absolute URL of alias is composed with canonical host
+			return e.domain, ""
+		}
+		return e.domain, hostname
+	}
+	return nil, ""
+}
+
+// defaultDomain method returns the domain marked as `default = true`
+// otherwise nil.
+func (r *Router) defaultDomain() *Domain {
+	for _, d := range r.Domains {
+		if d != nil && d.IsDefault {
+			return d
+		}
+	}
+	return nil
+}
+
+// scanDomains method returns the domain for given lowercase key by linear
+// scan of router domains, it is used until the host index is built.
+func (r *Router) scanDomains(key string) *Domain {
 	for _, d := range r.Domains {
 		if d.Key == key {
 			return d
@@ -254,6 +455,7 @@ func (r *Router) processRoutesConfig() (err error) {
 
 	// allocate for no. of domains
 	r.Domains = make([]*Domain, len(domains))
+	r.index = newHostIndex()
 	log.Debugf("Domain count: %d", len(domains))
 
 	for idx, key := range domains {
@@ -276,19 +478,43 @@ func (r *Router) processRoutesConfig() (err error) {
 			port = ""
 		}
 
+		// Router takes the URL scheme in the order they found-
+		//   1) routes.conf `domains.<domain-name>.scheme`
+		//   2) aah.conf `server.ssl.enable` is true then `https`
+		//   3) http
+		defaultScheme := schemeHTTP
+		if r.appConfig().BoolDefault("server.ssl.enable", false) {
+			defaultScheme = schemeHTTPS
+		}
+		urlScheme := strings.ToLower(strings.TrimSpace(domainCfg.StringDefault("scheme", defaultScheme)))
+		if urlScheme != schemeHTTP && urlScheme != schemeHTTPS {
+			err = fmt.Errorf("'%v.scheme' value '%s' is invalid, it should be either 'http' or 'https'", key, urlScheme)
+			return
+		}
+
 		domain := &Domain{
 			Name:                  domainCfg.StringDefault("name", key),
 			Host:                  host,
 			Port:                  port,
+			Scheme:                urlScheme,
 			IsSubDomain:           domainCfg.BoolDefault("subdomain", false),
+			AnyPort:               domainCfg.BoolDefault("any_port", false),
+			IsDefault:             domainCfg.BoolDefault("default", false),
+			CanonicalRedirect:     domainCfg.BoolDefault("canonical_redirect", false),
 			MethodNotAllowed:      domainCfg.BoolDefault("method_not_allowed", true),
 			RedirectTrailingSlash: domainCfg.BoolDefault("redirect_trailing_slash", true),
+			RedirectFixedPath:     domainCfg.BoolDefault("redirect_fixed_path", false),
+			CaseInsensitiveLookup: domainCfg.BoolDefault("case_insensitive_lookup", false),
+			UseRawPath:            domainCfg.BoolDefault("use_raw_path", false),
+			NamespaceNames:        domainCfg.BoolDefault("namespace_names", false),
 			AutoOptions:           domainCfg.BoolDefault("auto_options", true),
 			DefaultAuth:           domainCfg.StringDefault("default_auth", ""),
 			AntiCSRFEnabled:       domainCfg.BoolDefault("anti_csrf_check", true),
 			CORSEnabled:           domainCfg.BoolDefault("cors.enable", false),
 			trees:                 make(map[string]*node),
 			routes:                make(map[string]*Route),
+			hostParams:            parseHostParams(host),
+			maxBodySizeStr:        r.requestMaxBodySize(),
 		}
 
 		// Domain Level CORS configuration
@@ -313,6 +539,17 @@ func (r *Router) processRoutesConfig() (err error) {
 
 		// add domain routes
 		domain.inferKey()
+		if err = r.index.add(domain); err != nil {
+			err = fmt.Errorf("'%v.host' %s", key, err)
+			return
+		}
+		if err = r.processDomainAliases(key, domain, domainCfg); err != nil {
+			return
+		}
+		if d := r.defaultDomain(); d != nil && domain.IsDefault {
+			err = fmt.Errorf("'%v.default' is true, however domain '%s' is already default", key, d.Name)
+			return
+		}
 		log.Debugf("Domain: %s, routes found: %d", domain.Key, len(domain.routes))
 		if log.IsLevelTrace() { // process only if log level is trace
 			// Static Files routes
@@ -349,6 +586,28 @@ func (r *Router) processRoutesConfig() (err error) {
 	return
 }
 
+func (r *Router) processDomainAliases(key string, domain *Domain, domainCfg *config.Config) error {
+	aliases, found := domainCfg.StringList("aliases")
+	if !found || len(aliases) == 0 {
+		return nil
+	}
+
+	if len(domain.hostParams) > 0 {
+		return fmt.Errorf("'%v.aliases' is not supported, domain host '%s' has host params", key, domain.Host)
+	}
+	for _, alias := range aliases {
+		alias = strings.TrimSpace(alias)
+		if len(alias) == 0 || isHostPattern(alias) {
+			return fmt.Errorf("'%v.aliases' value '%s' is invalid, it should be an exact host", key, alias)
+		}
+		if err := r.index.addAlias(alias, domain); err != nil {
+			return fmt.Errorf("'%v.aliases' %s", key, err)
+		}
+		domain.Aliases = append(domain.Aliases, alias)
+	}
+	return nil
+}
+
 func (r *Router) processStaticRoutes(domain *Domain, domainCfg *config.Config) error {
 	staticCfg, found := domainCfg.GetSubConfig("static")
 	if !found {
@@ -360,10 +619,8 @@ func (r *Router) processStaticRoutes(domain *Domain, domainCfg *config.Config) e
 		return err
 	}
 
-	for idx := range routes {
-		if err = domain.AddRoute(routes[idx]); err != nil {
-			return err
-		}
+	if err = domain.AddRoutes(routes); err != nil {
+		return err
 	}
 
 	return nil
@@ -375,7 +632,7 @@ func (r *Router) processRoutes(domain *Domain, domainCfg *config.Config) error {
 		return nil
 	}
 
-	maxBodySizeStr := r.appConfig().StringDefault("request.max_body_size", "5mb")
+	maxBodySizeStr := domain.maxBodySizeStr
 	routes, err := parseSectionRoutes(routesCfg, &parentRouteInfo{
 		Auth:              domain.DefaultAuth,
 		MaxBodySizeStr:    maxBodySizeStr,
@@ -383,15 +640,18 @@ func (r *Router) processRoutes(domain *Domain, domainCfg *config.Config) error {
 		AntiCSRFCheck:     domain.AntiCSRFEnabled,
 		CORSEnabled:       domain.CORSEnabled,
 		AuthorizationInfo: &authorizationInfo{Satisfy: "either"},
+		NamespaceNames:    domain.NamespaceNames,
 	})
 	if err != nil {
 		return err
 	}
 
-	for idx := range routes {
-		if err = domain.AddRoute(routes[idx]); err != nil {
-			return err
-		}
+	if err = domain.checkDuplicateNames(routes); err != nil {
+		return err
+	}
+
+	if err = domain.AddRoutes(routes); err != nil {
+		return err
 	}
 
 	// Add form login route per security.conf for configured domains
@@ -442,6 +702,15 @@ func (r *Router) appConfig() *config.Config {
 	return r.aCfg
 }
 
+// requestMaxBodySize method returns the app config `request.max_body_size`
+// value, default is `5mb`.
+func (r *Router) requestMaxBodySize() string {
+	if r.aCfg == nil && r.app == nil {
+		return defaultMaxBodySize
+	}
+	return r.appConfig().StringDefault("request.max_body_size", defaultMaxBodySize)
+}
+
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
 // Unexported methods
 //______________________________________________________________________________
@@ -464,6 +733,13 @@ func parseSectionRoutes(cfg *config.Config, routeInfo *parentRouteInfo) (routes
 		}
 		routePath = path.Clean(strings.TrimSpace(routePath))
 
+		// route name is prefixed with parent route name on namespace names
+		// mode, for e.g.: `hotels_group.show_hotels`
+		fullRouteName := routeName
+		if routeInfo.NamespaceNames && len(routeInfo.ParentName) > 0 {
+			fullRouteName = routeInfo.ParentName + "." + routeName
+		}
+
 		// route segment parameter constraints
 		actualRoutePath, routeConstraints, er := parseRouteConstraints(routeName, routePath)
 		if er != nil {
@@ -506,9 +782,6 @@ func parseSectionRoutes(cfg *config.Config, routeInfo *parentRouteInfo) (routes
 		if er != nil {
 			log.Warnf("'%v.max_body_size' value is not a valid size unit, fallback to global limit", routeName)
 		}
-		if !payloadSupported.MatchString(routeMethod) {
-			routeMaxBodySize = 0
-		}
 
 		// getting Anti-CSRF check value, GitHub go-aah/aah#115
 		routeAntiCSRFCheck := cfg.BoolDefault(routeName+".anti_csrf_check", routeInfo.AntiCSRFCheck)
@@ -520,6 +793,39 @@ func parseSectionRoutes(cfg *config.Config, routeInfo *parentRouteInfo) (routes
 			return
 		}
 
+		// Route path param default values, applicable to optional params
+		var routeDefaults map[string]string
+		if defaultsCfg, found := cfg.GetSubConfig(routeName + ".defaults"); found {
+			routeDefaults = make(map[string]string)
+			for _, paramName := range defaultsCfg.Keys() {
+				routeDefaults[paramName] = defaultsCfg.StringDefault(paramName, "")
+			}
+		}
+
+		// Route meta and interceptors, inherited from parent and merged
+		routeMeta := mergeRouteMeta(routeInfo.Meta, parseRouteMeta(cfg, routeName+".meta"))
+		interceptors, _ := cfg.StringList(routeName + ".interceptors")
+		routeInterceptors := mergeValues(routeInfo.Interceptors, interceptors)
+
+		// Route description, tags, owner and deprecation; description is not
+		// inherited, tags are merged and rest are inherited from parent.
+		routeDescription := strings.TrimSpace(cfg.StringDefault(routeName+".description", ""))
+		tags, _ := cfg.StringList(routeName + ".tags")
+		routeTags := mergeValues(routeInfo.Tags, tags)
+		routeOwner := strings.TrimSpace(cfg.StringDefault(routeName+".owner", routeInfo.Owner))
+		routeSunset := routeInfo.Sunset
+		sunset, sunsetFound := cfg.String(routeName + ".sunset")
+		if sunsetFound {
+			if routeSunset, er = parseSunsetDate(sunset); er != nil {
+				err = fmt.Errorf("'%v.sunset' value '%s' is invalid, it should be date '2006-01-02' or RFC3339 format", routeName, sunset)
+				return
+			}
+		}
+		routeDeprecated := cfg.BoolDefault(routeName+".deprecated", routeInfo.Deprecated || sunsetFound)
+		if !routeDeprecated {
+			routeSunset = time.Time{}
+		}
+
 		// CORS
 		var cors *CORS
 		if routeInfo.CORSEnabled && routeMethod != methodWebSocket {
@@ -532,36 +838,35 @@ func parseSectionRoutes(cfg *config.Config, routeInfo *parentRouteInfo) (routes
 			}
 		}
 
-		// 'anti_csrf_check', 'cors' and 'max_body_size' not applicable for WebSocket
-		if routeMethod == methodWebSocket {
-			routeAntiCSRFCheck = false
-			cors = nil
-			routeMaxBodySize = 0
-		}
-
 		if notToSkip {
-			for _, m := range strings.Split(routeMethod, ",") {
-				routes = append(routes, &Route{
-					Name:              routeName,
-					Path:              actualRoutePath,
-					Method:            strings.TrimSpace(m),
-					Target:            routeTarget,
-					Action:            routeAction,
-					ParentName:        routeInfo.ParentName,
-					Auth:              routeAuth,
-					MaxBodySize:       routeMaxBodySize,
-					IsAntiCSRFCheck:   routeAntiCSRFCheck,
-					CORS:              cors,
-					Constraints:       routeConstraints,
-					authorizationInfo: routeAuthorizationInfo,
-				})
-			}
+			routes = append(routes, newRoutes(Route{
+				Name:              fullRouteName,
+				Path:              actualRoutePath,
+				Method:            routeMethod,
+				Target:            routeTarget,
+				Action:            routeAction,
+				ParentName:        routeInfo.ParentName,
+				Auth:              routeAuth,
+				MaxBodySize:       routeMaxBodySize,
+				IsAntiCSRFCheck:   routeAntiCSRFCheck,
+				CORS:              cors,
+				Constraints:       routeConstraints,
+				Defaults:          routeDefaults,
+				Meta:              routeMeta,
+				Interceptors:      routeInterceptors,
+				Description:       routeDescription,
+				Tags:              routeTags,
+				Owner:             routeOwner,
+				Deprecated:        routeDeprecated,
+				Sunset:            routeSunset,
+				authorizationInfo: routeAuthorizationInfo,
+			})...)
 		}
 
 		// loading child routes
 		if childRoutes, found := cfg.GetSubConfig(routeName + ".routes"); found {
 			croutes, er := parseSectionRoutes(childRoutes, &parentRouteInfo{
-				ParentName:        routeName,
+				ParentName:        fullRouteName,
 				PrefixPath:        routePath,
 				Target:            routeTarget,
 				Auth:              routeAuth,
@@ -570,6 +875,13 @@ func parseSectionRoutes(cfg *config.Config, routeInfo *parentRouteInfo) (routes
 				CORS:              cors,
 				CORSEnabled:       routeInfo.CORSEnabled,
 				AuthorizationInfo: routeAuthorizationInfo,
+				NamespaceNames:    routeInfo.NamespaceNames,
+				Meta:              routeMeta,
+				Interceptors:      routeInterceptors,
+				Tags:              routeTags,
+				Owner:             routeOwner,
+				Deprecated:        routeDeprecated,
+				Sunset:            routeSunset,
 			})
 			if er != nil {
 				err = er
@@ -583,6 +895,25 @@ func parseSectionRoutes(cfg *config.Config, routeInfo *parentRouteInfo) (routes
 	return
 }
 
+// parseRouteMeta method returns the route meta values from given key, nested
+// meta section becomes nested map.
+func parseRouteMeta(cfg *config.Config, key string) map[string]interface{} {
+	metaCfg, found := cfg.GetSubConfig(key)
+	if !found {
+		return nil
+	}
+
+	meta := make(map[string]interface{})
+	for _, k := range metaCfg.Keys() {
+		if _, found := metaCfg.GetSubConfig(k); found {
+			meta[k] = parseRouteMeta(metaCfg, k)
+		} else if v, found := metaCfg.Get(k); found {
+			meta[k] = v
+		}
+	}
+	return meta
+}
+
 func parseStaticSection(cfg *config.Config) (routes []*Route, err error) {
 	for _, routeName := range cfg.Keys() {
 		route := &Route{Name: routeName, Method: ahttp.MethodGet, IsStatic: true}
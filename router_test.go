@@ -6,6 +6,7 @@ package router
 
 import (
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
@@ -13,6 +14,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"aahframework.org/ahttp.v0"
 	"aahframework.org/config.v0"
@@ -84,12 +86,20 @@ func TestRouterLoadConfiguration(t *testing.T) {
 	})
 	assert.Equal(t, "router: method value is empty", err.Error())
 
+	// param co-exists with static edges
 	err = domain.AddRoute(&Route{
-		Name:   "ErrorRoute",
+		Name:   "UserTestRoute",
 		Path:   "/:user/test",
 		Method: "GET",
 	})
-	assert.True(t, strings.HasPrefix(err.Error(), "wildcard route ':user' conflicts"))
+	assert.Nil(t, err)
+
+	err = domain.AddRoute(&Route{
+		Name:   "ErrorRoute",
+		Path:   "/:account/test",
+		Method: "GET",
+	})
+	assert.True(t, strings.HasPrefix(err.Error(), "':account' in new path '/:account/test' conflicts with existing wildcard ':user'"))
 
 	domain.Port = ""
 	domain.inferKey()
@@ -189,6 +199,191 @@ func TestRouterErrorPathLoadConfiguration(t *testing.T) {
 	assert.Equal(t, "'app_index.path' key is missing", err.Error())
 }
 
+func TestRouterMultiMethodRouteNames(t *testing.T) {
+	router, err := createRouter("routes.conf")
+	assert.FailNowOnError(t, err, "")
+	domain := router.Lookup("localhost:8080")
+
+	routes := domain.RoutesByName("hotel_edit_settings")
+	assert.Equal(t, 2, len(routes))
+	assert.Equal(t, ahttp.MethodPost, domain.LookupByName("hotel_edit_settings").Method)
+
+	route := domain.LookupByNameMethod("hotel_edit_settings", "put")
+	assert.NotNil(t, route)
+	assert.Equal(t, ahttp.MethodPut, route.Method)
+	assert.Equal(t, "EditSettings", route.Action)
+	assert.Equal(t, ahttp.MethodPost, domain.LookupByNameMethod("hotel_edit_settings", ahttp.MethodPost).Method)
+	assert.Nil(t, domain.LookupByNameMethod("hotel_edit_settings", ahttp.MethodGet))
+	assert.Nil(t, domain.LookupByNameMethod("not_exists_routename", ahttp.MethodGet))
+	assert.Equal(t, "/settings", domain.RouteURL("hotel_edit_settings"))
+
+	// duplicate route name across unrelated routes
+	domain = &Domain{
+		Host:   "localhost",
+		trees:  make(map[string]*node),
+		routes: make(map[string]*Route),
+	}
+	assert.Nil(t, domain.AddRoute(&Route{Name: "show", Path: "/show", Method: ahttp.MethodGet, Target: "App", Action: "Show"}))
+	assert.Nil(t, domain.AddRoute(&Route{Name: "show", Path: "/show", Method: ahttp.MethodHead, Target: "App", Action: "Show"}))
+	err = domain.AddRoute(&Route{Name: "show", Path: "/hotels/:id", Method: ahttp.MethodGet, Target: "Hotel", Action: "Show", ParentName: "hotels"})
+	assert.Equal(t, "router: duplicate route name 'show', defined as [GET /show => App.Show] and [GET /hotels/:id => Hotel.Show, parent hotels]", err.Error())
+	err = domain.AddRoute(&Route{Name: "show", Path: "/show", Method: ahttp.MethodGet, Target: "App", Action: "Show"})
+	assert.NotNil(t, err)
+	assert.Equal(t, 2, len(domain.RoutesByName("show")))
+
+}
+
+func TestRouterDuplicateRouteNames(t *testing.T) {
+	router, err := createRouter("routes-duplicate-name-error.conf")
+	assert.Nil(t, router)
+	assert.NotNil(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "router: domain 'localhost' has duplicate route names: 'edit' defined as ["))
+	assert.True(t, strings.Contains(err.Error(), "; 'show' defined as [GET /hotels/:id => Hotel.Show, parent hotels] and [GET /rooms/:id => Room.Show, parent rooms]") ||
+		strings.Contains(err.Error(), "; 'show' defined as [GET /rooms/:id => Room.Show, parent rooms] and [GET /hotels/:id => Hotel.Show, parent hotels]"))
+
+	// namespace names mode
+	router, err = createRouter("routes-namespace-names.conf")
+	assert.FailNowOnError(t, err, "")
+	domain := router.Lookup("localhost:8080")
+	assert.True(t, domain.NamespaceNames)
+
+	for name, path := range map[string]string{
+		"edit":            "/edit",
+		"hotels":          "/hotels",
+		"hotels.show":     "/hotels/:id",
+		"rooms.show":      "/rooms/:id",
+		"rooms.show.edit": "/rooms/:id/edit",
+	} {
+		route := domain.LookupByName(name)
+		assert.NotNilf(t, route, "route '%s'", name)
+		assert.Equal(t, path, route.Path)
+	}
+	assert.Nil(t, domain.LookupByName("show"))
+	assert.Equal(t, "rooms.show", domain.LookupByName("rooms.show.edit").ParentName)
+	assert.Equal(t, 2, len(domain.RoutesByName("rooms.show.edit")))
+	assert.Equal(t, "/rooms/12/edit", domain.RouteURL("rooms.show.edit", 12))
+}
+
+func TestRouterRoutePathTokens(t *testing.T) {
+	route := &Route{
+		Path:        "/files/:name.:ext/*filepath",
+		Constraints: map[string]string{"ext": "oneof=pdf zip, regex([a-z]+)"},
+	}
+
+	tokens := route.PathTokens()
+	assert.Equal(t, 6, len(tokens))
+	assert.Equal(t, "/files/", tokens[0].Text)
+	assert.Equal(t, &PathParam{Name: "name"}, tokens[1].Param)
+	assert.Equal(t, ".", tokens[2].Text)
+	assert.Equal(t, &PathParam{Name: "ext", Constraint: "oneof=pdf zip, regex([a-z]+)",
+		ConstraintRules: []string{"oneof=pdf zip", "regex([a-z]+)"}}, tokens[3].Param)
+	assert.Equal(t, "/", tokens[4].Text)
+	assert.Equal(t, &PathParam{Name: "filepath", CatchAll: true}, tokens[5].Param)
+
+	tokens = (&Route{Path: "/reports/:period?", Constraints: map[string]string{"period": "int"}}).PathTokens()
+	assert.Equal(t, 2, len(tokens))
+	assert.Equal(t, &PathParam{Name: "period", Optional: true, Constraint: "int",
+		ConstraintRules: []string{"int"}}, tokens[1].Param)
+
+	params := route.PathParams()
+	assert.Equal(t, 3, len(params))
+	assert.Equal(t, "ext", params[1].Name)
+
+	assert.Nil(t, (&Route{Path: "/files/:name:ext"}).PathTokens())
+	assert.Nil(t, (&Route{Path: "/about"}).PathParams())
+
+	assert.True(t, (&Route{Name: "form_auth_login_submit__aah"}).IsAutoAdded())
+	assert.False(t, (&Route{Name: "login_submit"}).IsAutoAdded())
+}
+
+func TestRouterRouteMetaAndInterceptors(t *testing.T) {
+	router, err := createRouter("routes-meta.conf")
+	assert.FailNowOnError(t, err, "")
+	domain := router.Lookup("localhost:8080")
+
+	route := domain.LookupByName("health")
+	assert.Nil(t, route.Meta)
+	assert.Nil(t, route.Interceptors)
+
+	route = domain.LookupByName("orders")
+	assert.Equal(t, 3, len(route.Meta))
+	assert.Equal(t, "100", fmt.Sprint(route.Meta["rate_limit"]))
+	assert.Equal(t, true, route.Meta["audit"])
+	assert.Equal(t, map[string]interface{}{"ttl": "5m"}, route.Meta["cache"])
+	assert.Equal(t, []string{"request_id", "metrics"}, route.Interceptors)
+
+	for _, name := range []string{"users", "show_user"} {
+		route = domain.LookupByName(name)
+		assert.Equal(t, 4, len(route.Meta))
+		assert.Equal(t, "10", fmt.Sprint(route.Meta["rate_limit"]))
+		assert.Equal(t, "identity", route.Meta["team"])
+		assert.Equal(t, true, route.Meta["audit"])
+		assert.Equal(t, []string{"request_id", "metrics", "tenant"}, route.Interceptors)
+	}
+
+	// parent meta is not changed by merge
+	assert.Equal(t, "100", fmt.Sprint(domain.LookupByName("orders").Meta["rate_limit"]))
+
+	// builder
+	d := (&Router{}).AddDomain("localhost", "8080")
+	api := d.Group("/api").Meta("audit", true).Interceptors("request_id", "metrics")
+	api.GET("/orders", "Order", "List")
+	api.Group("/users").Meta("team", "identity").Interceptors("metrics", "tenant").
+		GET("/:id", "User", "Show")
+	assert.Nil(t, api.Err())
+
+	route = d.LookupByName("order_list")
+	assert.Equal(t, map[string]interface{}{"audit": true}, route.Meta)
+	assert.Equal(t, []string{"request_id", "metrics"}, route.Interceptors)
+
+	route = d.LookupByName("user_show")
+	assert.Equal(t, map[string]interface{}{"audit": true, "team": "identity"}, route.Meta)
+	assert.Equal(t, []string{"request_id", "metrics", "tenant"}, route.Interceptors)
+}
+
+func TestRouterRouteInfo(t *testing.T) {
+	router, err := createRouter("routes-route-info.conf")
+	assert.FailNowOnError(t, err, "")
+	domain := router.Lookup("localhost:8080")
+
+	route := domain.LookupByName("list_users")
+	assert.Equal(t, "List the users", route.Description)
+	assert.Equal(t, []string{"users", "list"}, route.Tags)
+	assert.Equal(t, "identity-team", route.Owner)
+	assert.True(t, route.Deprecated)
+	assert.Equal(t, time.Date(2019, 6, 30, 0, 0, 0, 0, time.UTC), route.Sunset)
+
+	route = domain.LookupByName("show_user")
+	assert.Equal(t, "", route.Description)
+	assert.Equal(t, []string{"users"}, route.Tags)
+	assert.Equal(t, "profile-team", route.Owner)
+	assert.False(t, route.Deprecated)
+	assert.True(t, route.Sunset.IsZero())
+
+	// sunset implies deprecated
+	route = domain.LookupByName("v2_users")
+	assert.Nil(t, route.Tags)
+	assert.True(t, route.Deprecated)
+	assert.Equal(t, time.Date(2020, 1, 15, 10, 0, 0, 0, time.UTC), route.Sunset)
+
+	_, err = createRouter("routes-route-info-error.conf")
+	assert.NotNil(t, err)
+	assert.Equal(t, "'users.sunset' value '30-06-2019' is invalid, it should be date '2006-01-02' or RFC3339 format", err.Error())
+
+	// builder
+	sunset := time.Date(2019, 6, 30, 0, 0, 0, 0, time.UTC)
+	d := (&Router{}).AddDomain("localhost", "8080")
+	v1 := d.Group("/v1").Tags("users").Owner("identity-team").Deprecated(sunset)
+	v1.Group("/users").Tags("list").GET("", "User", "List")
+	assert.Nil(t, v1.Err())
+
+	route = d.LookupByName("user_list")
+	assert.Equal(t, []string{"users", "list"}, route.Tags)
+	assert.Equal(t, "identity-team", route.Owner)
+	assert.True(t, route.Deprecated)
+	assert.Equal(t, sunset, route.Sunset)
+}
+
 func TestRouterErrorControllerLoadConfiguration(t *testing.T) {
 	router, err := createRouter("routes-controller-error.conf")
 	assert.NotNilf(t, err, "expected error loading '%v'", "routes-controller-error.conf")
@@ -358,6 +553,505 @@ func TestRouterDomainRouteURL(t *testing.T) {
 	assert.Equal(t, "", bookingURL)
 }
 
+func TestRouterDomainMultiParamSegment(t *testing.T) {
+	domain := &Domain{
+		Host:   "localhost",
+		trees:  make(map[string]*node),
+		routes: make(map[string]*Route),
+	}
+
+	for _, r := range []*Route{
+		{Name: "download", Path: "/files/:name.:ext", Method: "GET", Constraints: map[string]string{"ext": "oneof=pdf zip"}},
+		{Name: "docs", Path: "/v:major.:minor/docs", Method: "GET", Constraints: map[string]string{"major": "int", "minor": "int"}},
+		{Name: "archive", Path: "/archive/:year-:month", Method: "GET"},
+	} {
+		assert.Nil(t, domain.AddRoute(r))
+	}
+
+	getReq := func(path string) *http.Request {
+		req := createHTTPRequest("localhost", path)
+		req.Method = ahttp.MethodGet
+		return req
+	}
+
+	route, pathParams, rts := domain.Lookup(getReq("/files/report.final.pdf"))
+	assert.Equal(t, "download", route.Name)
+	assert.Equal(t, "report.final", pathParams.Get("name"))
+	assert.Equal(t, "pdf", pathParams.Get("ext"))
+	assert.False(t, rts)
+
+	route, _, _, err := domain.LookupWithError(getReq("/files/report.txt"))
+	assert.Equal(t, ErrRouteConstraintFailed, err)
+	assert.Equal(t, "download", route.Name)
+
+	route, pathParams, _ = domain.Lookup(getReq("/v2.10/docs"))
+	assert.Equal(t, "docs", route.Name)
+	assert.Equal(t, ahttp.PathParams{"major": "2", "minor": "10"}, pathParams)
+
+	route, pathParams, _ = domain.Lookup(getReq("/archive/2017-10"))
+	assert.Equal(t, "archive", route.Name)
+	assert.Equal(t, ahttp.PathParams{"year": "2017", "month": "10"}, pathParams)
+
+	// reverse route
+	assert.Equal(t, "/files/report.pdf", domain.RouteURL("download", "report", "pdf"))
+	assert.Equal(t, "/v2.10/docs", domain.RouteURL("docs", 2, 10))
+	assert.Equal(t, "/archive/2017-10", domain.RouteURLNamedArgs("archive", map[string]interface{}{
+		"year":  2017,
+		"month": "10",
+	}))
+	assert.Equal(t, "/files/report.pdf?v=2", domain.RouteURLNamedArgs("download", map[string]interface{}{
+		"name": "report",
+		"ext":  "pdf",
+		"v":    2,
+	}))
+	assert.Equal(t, "", domain.RouteURLNamedArgs("download", map[string]interface{}{
+		"name": "report",
+		"type": "pdf",
+	}))
+}
+
+type testUserID int
+
+func (id testUserID) String() string { return fmt.Sprintf("u-%d", int(id)) }
+
+type testSKU struct{ code string }
+
+func (s testSKU) MarshalText() ([]byte, error) {
+	if len(s.code) == 0 {
+		return nil, errors.New("sku code is empty")
+	}
+	return []byte(strings.ToUpper(s.code)), nil
+}
+
+func TestRouterDomainRouteURLEscaping(t *testing.T) {
+	domain := &Domain{
+		Host:   "localhost",
+		trees:  make(map[string]*node),
+		routes: make(map[string]*Route),
+	}
+
+	for _, r := range []*Route{
+		{Name: "search", Path: "/search/:query/page/:page", Method: ahttp.MethodGet},
+		{Name: "user", Path: "/users/:id", Method: ahttp.MethodGet},
+		{Name: "product", Path: "/products/:sku/:since", Method: ahttp.MethodGet},
+		{Name: "assets", Path: "/assets/*filepath", Method: ahttp.MethodGet},
+		{Name: "user_slash", Path: "/users/:id/", Method: ahttp.MethodGet},
+	} {
+		assert.Nil(t, domain.AddRoute(r))
+	}
+
+	// values are escaped per path segment
+	assert.Equal(t, "/search/go%20router%3F%23top/page/2", domain.RouteURL("search", "go router?#top", 2))
+	assert.Equal(t, "/search/100%25/page/1?sort=a+b", domain.RouteURLNamedArgs("search", map[string]interface{}{
+		"query": "100%",
+		"page":  1,
+		"sort":  "a b",
+	}))
+	assert.Equal(t, "/assets/css/my%20app.css", domain.RouteURL("assets", "/css/my app.css"))
+
+	// path is not cleaned, trailing slash is retained
+	assert.Equal(t, "/assets/css/", domain.RouteURL("assets", "/css/"))
+	assert.Equal(t, "/assets/", domain.RouteURL("assets", ""))
+	assert.Equal(t, "/users/5/", domain.RouteURL("user_slash", 5))
+
+	// values which changes the path structure
+	assert.Equal(t, "", domain.RouteURL("user", ".."))
+	assert.Equal(t, "", domain.RouteURL("user", "."))
+	assert.Equal(t, "", domain.RouteURL("user", ""))
+	assert.Equal(t, "", domain.RouteURL("user", "5/edit"))
+	assert.Equal(t, "", domain.RouteURL("assets", "css/../../etc/passwd"))
+	assert.Equal(t, "", domain.RouteURLNamedArgs("user", map[string]interface{}{"id": "../admin"}))
+
+	// raw path allows path separator, however not the relative path segment
+	domain.UseRawPath = true
+	assert.Equal(t, "/users/5%2Fedit", domain.RouteURL("user", "5/edit"))
+	assert.Equal(t, "", domain.RouteURL("user", "5/../admin"))
+	assert.Equal(t, "", domain.RouteURL("user", "../admin"))
+	domain.UseRawPath = false
+
+	// Stringer and TextMarshaler aware formatting
+	since := time.Date(2018, 7, 27, 10, 30, 0, 0, time.UTC)
+	assert.Equal(t, "/users/u-5", domain.RouteURL("user", testUserID(5)))
+	assert.Equal(t, "/products/AB-12/2018-07-27T10:30:00Z", domain.RouteURL("product", testSKU{"ab-12"}, since))
+	assert.Equal(t, "/users/u-5?ref=u-7", domain.RouteURLNamedArgs("user", map[string]interface{}{
+		"id":  testUserID(5),
+		"ref": testUserID(7),
+	}))
+	assert.Equal(t, "/users/%C3%BCser%20one", domain.RouteURL("user", []byte("üser one")))
+	assert.Equal(t, "", domain.RouteURL("product", testSKU{}, since))
+	assert.Equal(t, "", domain.RouteURLNamedArgs("user", map[string]interface{}{
+		"id":  testUserID(5),
+		"sku": testSKU{},
+	}))
+}
+
+func TestRouterDomainRouteURLConstraints(t *testing.T) {
+	domain := &Domain{
+		Host:   "localhost",
+		trees:  make(map[string]*node),
+		routes: make(map[string]*Route),
+	}
+
+	for _, r := range []*Route{
+		{Name: "order", Path: "/orders/:id/items/:sku", Method: ahttp.MethodGet,
+			Constraints: map[string]string{"id": "int", "sku": "regex([A-Z]{2}-[0-9]+)"}},
+		{Name: "docs", Path: "/docs/*page", Method: ahttp.MethodGet,
+			Constraints: map[string]string{"page": "regex([a-z0-9/ -]+)"}},
+	} {
+		assert.Nil(t, domain.AddRoute(r))
+	}
+
+	reverseURL, err := domain.RouteURLE("order", 10, "AB-12")
+	assert.Nil(t, err)
+	assert.Equal(t, "/orders/10/items/AB-12", reverseURL)
+
+	_, err = domain.RouteURLE("order", "ten", "AB-12")
+	e, ok := err.(*RouteURLError)
+	assert.True(t, ok)
+	assert.Equal(t, ErrConstraintViolation, e.Err)
+	assert.Equal(t, "id", e.Param)
+	assert.Equal(t, "router: route param value violates constraint, route 'order' param 'id': value 'ten' does not satisfy constraint 'int'", err.Error())
+
+	_, err = domain.BuildURL("order", map[string]interface{}{"id": 10, "sku": "ab-12"})
+	assert.Equal(t, ErrConstraintViolation, err.(*RouteURLError).Err)
+	assert.Equal(t, "sku", err.(*RouteURLError).Param)
+
+	// catch-all keeps the slashes and escapes the rest
+	reverseURL, err = domain.RouteURLE("docs", "/guide/getting started")
+	assert.Nil(t, err)
+	assert.Equal(t, "/docs/guide/getting%20started", reverseURL)
+
+	_, err = domain.RouteURLE("docs", "guide/Intro.md")
+	assert.Equal(t, ErrConstraintViolation, err.(*RouteURLError).Err)
+	assert.Equal(t, "value 'guide/Intro.md' does not satisfy constraint 'regex([a-z0-9/ -]+)'", err.(*RouteURLError).Reason)
+
+	// catch-all constraint on lookup
+	getReq := func(path string) *http.Request {
+		req := createHTTPRequest("localhost", path)
+		req.Method = ahttp.MethodGet
+		return req
+	}
+
+	route, pathParams, _ := domain.Lookup(getReq("/docs/guide/getting started"))
+	assert.NotNil(t, route)
+	assert.Equal(t, "/guide/getting started", pathParams.Get("page"))
+
+	route, _, _ = domain.Lookup(getReq("/docs/guide/Intro.md"))
+	assert.Nil(t, route)
+}
+
+func TestRouterDomainBuildURLErrors(t *testing.T) {
+	router, err := createRouter("routes.conf")
+	assert.FailNowOnError(t, err, "")
+	domain := router.Lookup("localhost:8080")
+
+	routeURLErr := func(err error) *RouteURLError {
+		e, ok := err.(*RouteURLError)
+		if !ok {
+			t.Fatalf("expected *RouteURLError, got %T", err)
+		}
+		return e
+	}
+
+	// route name not exists
+	_, err = domain.RouteURLE("not_exists_routename")
+	e := routeURLErr(err)
+	assert.Equal(t, ErrRouteNotFound, e.Err)
+	assert.Equal(t, "router: route not found, route 'not_exists_routename'", err.Error())
+	_, err = domain.BuildURL("not_exists_routename", nil)
+	assert.Equal(t, ErrRouteNotFound, routeURLErr(err).Err)
+
+	// missing param
+	_, err = domain.RouteURLE("book_hotels")
+	e = routeURLErr(err)
+	assert.Equal(t, ErrMissingParam, e.Err)
+	assert.Equal(t, "id", e.Param)
+	assert.Equal(t, "router: missing route param value, route 'book_hotels' param 'id'", err.Error())
+
+	args := map[string]interface{}{"idvalue": "12345678"}
+	_, err = domain.BuildURL("book_hotels", args)
+	assert.Equal(t, ErrMissingParam, routeURLErr(err).Err)
+	assert.Equal(t, 1, len(args))
+
+	// too many params
+	_, err = domain.RouteURLE("book_hotels", 12345678, "param1value")
+	e = routeURLErr(err)
+	assert.Equal(t, ErrTooManyParams, e.Err)
+	assert.Equal(t, "router: too many route param values, route 'book_hotels': path '/hotels/:id/booking' params count 1, supplied values count 2", err.Error())
+	_, err = domain.RouteURLE("login", "value")
+	assert.Equal(t, ErrTooManyParams, routeURLErr(err).Err)
+
+	// constraint violation
+	_, err = domain.RouteURLE("book_hotels", "../admin")
+	e = routeURLErr(err)
+	assert.Equal(t, ErrConstraintViolation, e.Err)
+	assert.Equal(t, "router: route param value violates constraint, route 'book_hotels' param 'id': value '../admin' has path separator", err.Error())
+
+	_, err = domain.BuildURL("book_hotels", map[string]interface{}{"id": 5, "sku": testSKU{}})
+	e = routeURLErr(err)
+	assert.Equal(t, ErrConstraintViolation, e.Err)
+	assert.Equal(t, "sku", e.Param)
+	assert.Equal(t, "sku code is empty", e.Reason)
+
+	// success
+	reverseURL, err := domain.RouteURLE("book_hotels", 12345678)
+	assert.Nil(t, err)
+	assert.Equal(t, "/hotels/12345678/booking", reverseURL)
+
+	args = map[string]interface{}{"id": 12345678, "param1": "param1value"}
+	reverseURL, err = domain.BuildURL("book_hotels", args)
+	assert.Nil(t, err)
+	assert.Equal(t, "/hotels/12345678/booking?param1=param1value", reverseURL)
+	assert.Equal(t, 2, len(args))
+
+	reverseURL, err = domain.BuildURL("login", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "/login", reverseURL)
+}
+
+func TestRouterAbsoluteURL(t *testing.T) {
+	router, err := createRouter("routes-absolute-url.conf")
+	assert.FailNowOnError(t, err, "")
+
+	// scheme and port from domain config
+	assert.Equal(t, "https://sample.com/", router.AbsoluteURL("sample.com", "index", nil))
+	assert.Equal(t, "https://sample.com/users/10/profile?tab=billing", router.AbsoluteURL("Sample.com", "user_profile",
+		map[string]interface{}{"id": 10, "tab": "billing"}))
+	assert.Equal(t, "https://admin.sample.com:8443/dashboard", router.AbsoluteURL("admin.sample.com", "dashboard", nil))
+	assert.Equal(t, "https://admin.sample.com:8443/dashboard", router.AbsoluteURL("admin.sample.com:8443", "dashboard", nil))
+
+	// wildcard subdomain from argument
+	args := map[string]interface{}{SubdomainArg: "tenant1", "number": "INV-1001"}
+	assert.Equal(t, "http://tenant1.sample.com/invoices/INV-1001", router.AbsoluteURL("*.sample.com", "invoice", args))
+	assert.Equal(t, 2, len(args))
+
+	// wildcard subdomain from host
+	assert.Equal(t, "http://tenant2.sample.com/", router.AbsoluteURL("tenant2.sample.com", "home", nil))
+	assert.Equal(t, "http://tenant2.sample.com/", router.AbsoluteURL("tenant2.sample.com", "home",
+		map[string]interface{}{SubdomainArg: "tenant1"}))
+
+	// errors
+	routeURLErr := func(err error) *RouteURLError {
+		e, ok := err.(*RouteURLError)
+		if !ok {
+			t.Fatalf("expected *RouteURLError, got %T", err)
+		}
+		return e
+	}
+
+	_, err = router.BuildAbsoluteURL("aahframework.org", "index", nil)
+	assert.Equal(t, ErrDomainNotFound, routeURLErr(err).Err)
+	assert.Equal(t, "router: domain not found, route 'index': host 'aahframework.org'", err.Error())
+	assert.Equal(t, "", router.AbsoluteURL("aahframework.org", "index", nil))
+
+	_, err = router.BuildAbsoluteURL("sample.com", "dashboard", nil)
+	assert.Equal(t, ErrRouteNotFound, routeURLErr(err).Err)
+
+	_, err = router.BuildAbsoluteURL("*.sample.com", "home", nil)
+	e := routeURLErr(err)
+	assert.Equal(t, ErrMissingParam, e.Err)
+	assert.Equal(t, SubdomainArg, e.Param)
+
+	_, err = router.BuildAbsoluteURL("*.sample.com", "home", map[string]interface{}{SubdomainArg: "tenant_1/"})
+	e = routeURLErr(err)
+	assert.Equal(t, ErrConstraintViolation, e.Err)
+	assert.Equal(t, "value 'tenant_1/' is not a valid subdomain", e.Reason)
+
+	_, err = router.BuildAbsoluteURL("tenant1.sample.com", "invoice", nil)
+	assert.Equal(t, ErrMissingParam, routeURLErr(err).Err)
+
+	// invalid scheme
+	_, err = createRouter("routes-absolute-url-error.conf")
+	assert.NotNil(t, err)
+	assert.Equal(t, "'sample_com.scheme' value 'ftp' is invalid, it should be either 'http' or 'https'", err.Error())
+}
+
+func TestRouterHostPatterns(t *testing.T) {
+	router, err := createRouter("routes-hosts.conf")
+	assert.FailNowOnError(t, err, "")
+	assert.Equal(t, "sample_com", router.RootDomain().Name)
+
+	for host, name := range map[string]string{
+		"sample.com:8080":         "sample_com",
+		"Sample.com:8080":         "sample_com",
+		"tenant1.sample.com:8080": "wildcard_sample_com",
+		"a.b.sample.com:8080":     "wildcard_sample_com",
+		"api-v2.sample.com:8080":  "api_sample_com",
+		"api.eu.sample.com:8080":  "eu_sample_com",
+		"a.b.eu.sample.com:8080":  "eu_sample_com",
+		"sample.com:9090":         "",
+		"tenant1.sample.com":      "",
+		"tenant1.sample.org:8080": "",
+	} {
+		domain := router.Lookup(host)
+		if len(name) == 0 {
+			assert.Nilf(t, domain, "host '%s'", host)
+			continue
+		}
+		assert.NotNilf(t, domain, "host '%s'", host)
+		assert.Equal(t, name, domain.Name)
+	}
+
+	// absolute URL with actual host of the pattern
+	assert.Equal(t, "http://api-v2.sample.com:8080/", router.AbsoluteURL("api-v2.sample.com", "index", nil))
+	assert.Equal(t, "http://a.b.sample.com:8080/", router.AbsoluteURL("A.b.sample.com:8080", "index", nil))
+	assert.Equal(t, "http://tenant1.sample.com:8080/", router.AbsoluteURL("*.sample.com", "index",
+		map[string]interface{}{SubdomainArg: "tenant1"}))
+
+	_, err = router.BuildAbsoluteURL("api-*.sample.com", "index", nil)
+	assert.Equal(t, "router: missing route param value, route 'index': domain host 'api-*.sample.com' is a pattern, supply the actual host", err.Error())
+
+	// conflict host
+	_, err = createRouter("routes-hosts-error.conf")
+	assert.NotNil(t, err)
+	assert.True(t, strings.Contains(err.Error(), "conflicts with domain"))
+}
+
+func TestRouterHostParams(t *testing.T) {
+	router, err := createRouter("routes-host-params.conf")
+	assert.FailNowOnError(t, err, "")
+
+	assert.Equal(t, "admin_app_com", router.Lookup("admin.app.com:8080").Name)
+	domain := router.Lookup("acme.app.com:8080")
+	assert.Equal(t, "tenant_app_com", domain.Name)
+
+	// host params along with path params
+	req := createHTTPRequest("Acme.app.com:8080", "/users/10")
+	req.Method = ahttp.MethodGet
+	route, pathParams, _ := domain.Lookup(req)
+	assert.Equal(t, "user", route.Name)
+	assert.Equal(t, "acme", pathParams.Get("tenant"))
+	assert.Equal(t, "10", pathParams.Get("id"))
+
+	pb := AcquireParamsBuffer()
+	defer ReleaseParamsBuffer(pb)
+	route, _ = domain.LookupInto(req, pb)
+	assert.Equal(t, "user", route.Name)
+	assert.Equal(t, "acme", pb.Get("tenant"))
+	assert.Equal(t, 2, pb.Len())
+
+	// defaults and host params along with path params
+	req = createHTTPRequest("acme.app.com:8080", "/users/10/posts")
+	req.Method = ahttp.MethodGet
+	pb = &ParamsBuffer{}
+	route, _ = domain.LookupInto(req, pb)
+	assert.Equal(t, "user_posts", route.Name)
+	assert.Equal(t, 3, pb.Len())
+	assert.Equal(t, "10", pb.Get("id"))
+	assert.Equal(t, "1", pb.Get("page"))
+	assert.Equal(t, "acme", pb.Get("tenant"))
+	assert.Equal(t, 3, cap(pb.params))
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = domain.LookupInto(req, pb)
+	})
+	assert.Equal(t, float64(0), allocs)
+
+	// absolute URL
+	assert.Equal(t, "http://acme.app.com:8080/users/10", router.AbsoluteURL(":tenant.app.com", "user",
+		map[string]interface{}{"tenant": "acme", "id": 10}))
+	assert.Equal(t, "http://acme.app.com:8080/users/10", router.AbsoluteURL("acme.app.com", "user",
+		map[string]interface{}{"tenant": "other", "id": 10}))
+
+	_, err = router.BuildAbsoluteURL(":tenant.app.com", "user", map[string]interface{}{"id": 10})
+	e := err.(*RouteURLError)
+	assert.Equal(t, ErrMissingParam, e.Err)
+	assert.Equal(t, "tenant", e.Param)
+	assert.Equal(t, "domain host ':tenant.app.com' has host param", e.Reason)
+
+	_, err = router.BuildAbsoluteURL(":tenant.app.com", "user", map[string]interface{}{"tenant": "a.b", "id": 10})
+	e = err.(*RouteURLError)
+	assert.Equal(t, ErrConstraintViolation, e.Err)
+	assert.Equal(t, "value 'a.b' is not a valid host label", e.Reason)
+
+	// route path param conflicts with host param
+	_, err = createRouter("routes-host-params-error.conf")
+	assert.NotNil(t, err)
+	assert.Equal(t, "router: route 'tenant_user' path param 'tenant' conflicts with domain host param", err.Error())
+}
+
+func TestRouterHostNormalization(t *testing.T) {
+	SetHostMapper(testHostMapper)
+	defer SetHostMapper(nil)
+
+	router, err := createRouter("routes-host-normalize.conf")
+	assert.FailNowOnError(t, err, "")
+	assert.True(t, router.Lookup("proxy.sample.com:8080").AnyPort)
+
+	for host, name := range map[string]string{
+		"sample.com":                 "sample_com",
+		"Sample.com:443":             "sample_com",
+		"sample.com:80":              "sample_com",
+		"sample.com.":                "sample_com",
+		"sample.com:8443":            "",
+		"proxy.sample.com:8080":      "proxy_sample_com",
+		"proxy.sample.com:8443":      "proxy_sample_com",
+		"proxy.sample.com":           "proxy_sample_com",
+		"Bücher.example:443":         "idn_example",
+		"xn--bcher-kva.example":      "idn_example",
+		"xn--bcher-kva.example.:443": "idn_example",
+	} {
+		domain := router.Lookup(host)
+		if len(name) == 0 {
+			assert.Nilf(t, domain, "host '%s'", host)
+			continue
+		}
+		assert.NotNilf(t, domain, "host '%s'", host)
+		assert.Equal(t, name, domain.Name)
+	}
+
+	assert.Equal(t, "http://sample.com/", router.AbsoluteURL("sample.com.:443", "index", nil))
+}
+
+func TestRouterDomainAliases(t *testing.T) {
+	router, err := createRouter("routes-aliases.conf")
+	assert.FailNowOnError(t, err, "")
+
+	for host, name := range map[string]string{
+		"sample.com":             "sample_com",
+		"www.sample.com:8080":    "sample_com",
+		"sample.net:8080":        "sample_com",
+		"docs.sample.com:8080":   "docs_sample_com",
+		"manual.sample.com:8080": "docs_sample_com",
+		"unknown.com":            "sample_com",
+		"www.sample.com:9000":    "sample_com",
+	} {
+		domain := router.Lookup(host)
+		assert.NotNilf(t, domain, "host '%s'", host)
+		assert.Equal(t, name, domain.Name)
+	}
+
+	domain, redirect := router.LookupHost("www.sample.com:8080")
+	assert.True(t, redirect)
+	assert.Equal(t, "sample.com:8080", domain.CanonicalHost())
+	assert.Equal(t, []string{"www.sample.com", "Sample.net"}, domain.Aliases)
+
+	_, redirect = router.LookupHost("sample.com:8080")
+	assert.False(t, redirect)
+	_, redirect = router.LookupHost("manual.sample.com:8080")
+	assert.False(t, redirect)
+
+	// absolute URL of alias uses canonical host
+	assert.Equal(t, "http://sample.com:8080/", router.AbsoluteURL("www.sample.com", "index", nil))
+
+	assert.Nil(t, router.AddDomainAlias(domain, "sample.org"))
+	assert.Equal(t, "sample_com", router.Lookup("sample.org:8080").Name)
+
+	err = router.AddDomainAlias(&Domain{Name: "other", Host: "other.com"}, "other.org")
+	assert.Equal(t, "router: domain 'other' is not added into router", err.Error())
+	assert.Equal(t, "sample_com", router.Lookup("other.org:8080").Name) // default domain
+
+	err = router.AddDomainAlias(domain, "*.sample.org")
+	assert.Equal(t, "router: domain 'sample_com' alias '*.sample.org' is invalid, it should be an exact host", err.Error())
+
+	err = router.AddDomainAlias(router.Lookup("docs.sample.com:8080"), "sample.org")
+	assert.Equal(t, "router: domain 'docs_sample_com' alias value 'sample.org' conflicts with domain 'sample_com'", err.Error())
+
+	_, err = createRouter("routes-aliases-error.conf")
+	assert.NotNil(t, err)
+	assert.Equal(t, "'www_sample_com.aliases' value 'sample.com' conflicts with domain 'sample_com'", err.Error())
+}
+
 func TestRouterDomainAddRoute(t *testing.T) {
 	domain := &Domain{
 		Host:   "aahframework.org",
@@ -470,6 +1164,285 @@ func TestRouterNamespaceSimplified2Config(t *testing.T) {
 	assert.Equal(t, errors.New(`'routes.path' has invalid contraint in path => '/v1/users/:id  gt=1,lt=10]' (param => ':id  gt=1,lt=10]')`), err)
 }
 
+func TestRouterDomainLookupConstraints(t *testing.T) {
+	router, err := createRouter("routes-simplified-2.conf")
+	assert.FailNowOnError(t, err, "")
+
+	domain := router.Lookup("localhost:8080")
+
+	// constraints satisfied, valpar validates the value length
+	req := createHTTPRequest("localhost:8080", "/v1/users/12345")
+	req.Method = ahttp.MethodGet
+	route, pathParams, rts, err := domain.LookupWithError(req)
+	assert.Nil(t, err)
+	assert.False(t, rts)
+	assert.Equal(t, "get_user", route.Name)
+	assert.Equal(t, "12345", pathParams.Get("id"))
+
+	// constraints failed
+	req = createHTTPRequest("localhost:8080", "/v1/users/1234567890")
+	req.Method = ahttp.MethodGet
+	route, pathParams, _, err = domain.LookupWithError(req)
+	assert.Equal(t, ErrRouteConstraintFailed, err)
+	assert.Equal(t, "get_user", route.Name)
+	assert.Equal(t, "1234567890", pathParams.Get("id"))
+
+	route, pathParams, rts = domain.Lookup(req)
+	assert.Nil(t, route)
+	assert.Nil(t, pathParams)
+	assert.False(t, rts)
+
+	// not found
+	req = createHTTPRequest("localhost:8080", "/v2/users/5")
+	req.Method = ahttp.MethodGet
+	route, _, _, err = domain.LookupWithError(req)
+	assert.Nil(t, err)
+	assert.Nil(t, route)
+}
+
+func TestRouterDomainLookupFixedPath(t *testing.T) {
+	router, err := createRouter("routes-fixed-path.conf")
+	assert.FailNowOnError(t, err, "")
+
+	domain := router.Lookup("localhost:8080")
+	assert.True(t, domain.RedirectFixedPath)
+	assert.True(t, domain.CaseInsensitiveLookup)
+
+	newReq := func(path string) *http.Request {
+		req := createHTTPRequest("localhost:8080", path)
+		req.Method = ahttp.MethodGet
+		return req
+	}
+
+	testcases := []struct {
+		path, fixedPath string
+		found           bool
+	}{
+		{"/V1//Users/5/", "/v1/users/5", true},
+		{"/v1/users/../Users/5/SETTINGS", "/v1/users/5/settings", true},
+		{"/v1/USERS/Ab5/", "/v1/users/Ab5", false},
+		{"/v2/users/5", "", false},
+	}
+	for _, tc := range testcases {
+		fixedPath, found := domain.LookupFixedPath(newReq(tc.path))
+		assert.Equalf(t, tc.found, found, "path: %s", tc.path)
+		if tc.found {
+			assert.Equal(t, tc.fixedPath, fixedPath)
+		}
+	}
+
+	// case-insensitive lookup
+	route, pathParams, rts := domain.Lookup(newReq("/V1/Users/5/Settings"))
+	assert.Equal(t, "get_user_settings", route.Name)
+	assert.Equal(t, "5", pathParams.Get("id"))
+	assert.False(t, rts)
+
+	route, _, rts = domain.Lookup(newReq("/V1/Users/5/"))
+	assert.Nil(t, route)
+	assert.True(t, rts)
+
+	domain.CaseInsensitiveLookup = false
+	route, _, rts = domain.Lookup(newReq("/V1/Users/5/Settings"))
+	assert.Nil(t, route)
+	assert.False(t, rts)
+
+	// no route tree for method
+	req := newReq("/V1/Users/5")
+	req.Method = ahttp.MethodPut
+	fixedPath, found := domain.LookupFixedPath(req)
+	assert.False(t, found)
+	assert.Equal(t, "", fixedPath)
+}
+
+func TestRouterDomainLookupInto(t *testing.T) {
+	router, err := createRouter("routes.conf")
+	assert.FailNowOnError(t, err, "")
+
+	domain := router.Lookup("localhost:8080")
+	pb := AcquireParamsBuffer()
+	defer ReleaseParamsBuffer(pb)
+
+	for _, p := range []string{"/", "/hotels/12345", "/hotels/12345/booking", "/hotels/12345/", "/not-exists"} {
+		req := createHTTPRequest("localhost:8080", p)
+		req.Method = ahttp.MethodGet
+		route, pathParams, rts := domain.Lookup(req)
+
+		routeInto, rtsInto := domain.LookupInto(req, pb)
+		assert.Equal(t, route, routeInto)
+		assert.Equal(t, rts, rtsInto)
+		assert.Equal(t, pathParams, pb.PathParams())
+		assert.Equal(t, len(pathParams), pb.Len())
+	}
+
+	req := createHTTPRequest("localhost:8080", "/hotels/12345/booking")
+	req.Method = ahttp.MethodGet
+	route, _ := domain.LookupInto(req, pb)
+	assert.Equal(t, "book_hotels", route.Name)
+	assert.Equal(t, 1, pb.Len())
+	assert.Equal(t, "id", pb.Key(0))
+	assert.Equal(t, "12345", pb.Value(0))
+	assert.Equal(t, "12345", pb.Get("id"))
+	assert.Equal(t, "", pb.Get("notexists"))
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = domain.LookupInto(req, pb)
+	})
+	assert.Equal(t, float64(0), allocs)
+
+	// mixed-case host, domain without host params
+	req.Host = "LocalHost:8080"
+	allocs = testing.AllocsPerRun(100, func() {
+		_, _ = domain.LookupInto(req, pb)
+	})
+	assert.Equal(t, float64(0), allocs)
+
+	// method not exists
+	req.Method = ahttp.MethodPut
+	route, rts := domain.LookupInto(req, pb)
+	assert.Nil(t, route)
+	assert.False(t, rts)
+	assert.Equal(t, 0, pb.Len())
+	assert.Nil(t, pb.PathParams())
+}
+
+func TestRouterDomainUseRawPath(t *testing.T) {
+	domain := &Domain{
+		Host:       "localhost",
+		UseRawPath: true,
+		trees:      make(map[string]*node),
+		routes:     make(map[string]*Route),
+	}
+
+	for _, r := range []*Route{
+		{Name: "file_meta", Path: "/files/:id/meta", Method: ahttp.MethodGet, Constraints: map[string]string{"id": "regex([a-z]+/[a-z]+)"}},
+		{Name: "file", Path: "/files/:id", Method: ahttp.MethodGet},
+		{Name: "assets", Path: "/assets/*filepath", Method: ahttp.MethodGet},
+	} {
+		assert.Nil(t, domain.AddRoute(r))
+	}
+
+	newReq := func(rawurl string) *http.Request {
+		u, err := url.Parse(rawurl)
+		assert.FailNowOnError(t, err, "")
+		return &http.Request{Method: ahttp.MethodGet, Host: "localhost", URL: u}
+	}
+
+	route, pathParams, _ := domain.Lookup(newReq("/files/docs%2Freport/meta"))
+	assert.Equal(t, "file_meta", route.Name)
+	assert.Equal(t, ahttp.PathParams{"id": "docs/report"}, pathParams)
+
+	route, pathParams, _ = domain.Lookup(newReq("/files/report%20v1.pdf"))
+	assert.Equal(t, "file", route.Name)
+	assert.Equal(t, ahttp.PathParams{"id": "report v1.pdf"}, pathParams)
+
+	route, pathParams, _ = domain.Lookup(newReq("/assets/css%2Fv1/app%20main.css"))
+	assert.Equal(t, "assets", route.Name)
+	assert.Equal(t, ahttp.PathParams{"filepath": "/css/v1/app main.css"}, pathParams)
+
+	// constraint validated against unescaped value
+	route, _, _, err := domain.LookupWithError(newReq("/files/docs%2F123/meta"))
+	assert.Equal(t, ErrRouteConstraintFailed, err)
+	assert.Equal(t, "file_meta", route.Name)
+
+	pb := AcquireParamsBuffer()
+	defer ReleaseParamsBuffer(pb)
+	route, _ = domain.LookupInto(newReq("/files/docs%2Freport/meta"), pb)
+	assert.Equal(t, "file_meta", route.Name)
+	assert.Equal(t, "docs/report", pb.Get("id"))
+
+	// routing on path, escaped slash splits the segment
+	domain.UseRawPath = false
+	route, _, _ = domain.Lookup(newReq("/files/docs%2Freport"))
+	assert.Nil(t, route)
+	assert.Equal(t, "", domain.RouteURL("file", "docs/report"))
+
+	// reverse route escapes the values
+	domain.UseRawPath = true
+	assert.Equal(t, "/files/docs%2Freport/meta", domain.RouteURL("file_meta", "docs/report"))
+	assert.Equal(t, "/files/report%20v1.pdf", domain.RouteURLNamedArgs("file", map[string]interface{}{"id": "report v1.pdf"}))
+	assert.Equal(t, "/assets/css/app%20main.css", domain.RouteURL("assets", "css/app main.css"))
+}
+
+func TestRouterOptionalSegments(t *testing.T) {
+	router, err := createRouter("routes-optional.conf")
+	assert.FailNowOnError(t, err, "")
+
+	domain := router.Lookup("localhost:8080")
+	lookup := func(path string) (*Route, ahttp.PathParams) {
+		req := createHTTPRequest("localhost:8080", path)
+		req.Method = ahttp.MethodGet
+		route, pathParams, _ := domain.Lookup(req)
+		return route, pathParams
+	}
+
+	reportsRoute := domain.LookupByName("reports")
+	assert.Equal(t, "/reports/:period?", reportsRoute.Path)
+	assert.Equal(t, map[string]string{"period": "month"}, reportsRoute.Defaults)
+
+	// default value injected
+	route, pathParams := lookup("/reports")
+	assert.Equal(t, "reports", route.Name)
+	assert.Equal(t, ahttp.PathParams{"period": "month"}, pathParams)
+
+	route, pathParams = lookup("/reports/week")
+	assert.Equal(t, "reports", route.Name)
+	assert.Equal(t, ahttp.PathParams{"period": "week"}, pathParams)
+
+	route, _ = lookup("/reports/year")
+	assert.Nil(t, route)
+
+	route, pathParams = lookup("/archive")
+	assert.Equal(t, "archive", route.Name)
+	assert.Nil(t, pathParams)
+
+	route, pathParams = lookup("/archive/2017")
+	assert.Equal(t, "archive", route.Name)
+	assert.Equal(t, ahttp.PathParams{"year": "2017"}, pathParams)
+
+	route, pathParams = lookup("/archive/2017/10")
+	assert.Equal(t, "archive", route.Name)
+	assert.Equal(t, ahttp.PathParams{"year": "2017", "month": "10"}, pathParams)
+
+	// reverse route, default value is omitted
+	assert.Equal(t, "/reports", domain.RouteURL("reports"))
+	assert.Equal(t, "/reports", domain.RouteURL("reports", "month"))
+	assert.Equal(t, "/reports/week", domain.RouteURL("reports", "week"))
+	assert.Equal(t, "/reports", domain.RouteURLNamedArgs("reports", map[string]interface{}{"period": "month"}))
+	assert.Equal(t, "/reports/day?page=2", domain.RouteURLNamedArgs("reports", map[string]interface{}{
+		"period": "day",
+		"page":   2,
+	}))
+	assert.Equal(t, "/archive", domain.RouteURL("archive"))
+	assert.Equal(t, "/archive/2017", domain.RouteURL("archive", 2017))
+	assert.Equal(t, "/archive/2017/10", domain.RouteURLNamedArgs("archive", map[string]interface{}{
+		"year":  2017,
+		"month": 10,
+	}))
+
+	// preceding optional param value is missing
+	assert.Equal(t, "", domain.RouteURLNamedArgs("archive", map[string]interface{}{"month": 10}))
+
+	// error scenarios
+	_, err = createRouter("routes-optional-error.conf")
+	assert.Equal(t, "'show_user.defaults' has 'id' which is not an optional param in path => '/users/:id'", err.Error())
+
+	err = domain.AddRoute(&Route{
+		Name:        "invalid_default",
+		Path:        "/invalid/:kind?",
+		Method:      ahttp.MethodGet,
+		Constraints: map[string]string{"kind": "int"},
+		Defaults:    map[string]string{"kind": "all"},
+	})
+	assert.Equal(t, "'invalid_default.defaults' value 'all' does not satisfy constraint 'int' on param 'kind' in path => '/invalid/:kind?'", err.Error())
+
+	err = domain.AddRoute(&Route{
+		Name:   "invalid_optional",
+		Path:   "/invalid/:kind?/list",
+		Method: ahttp.MethodGet,
+	})
+	assert.Equal(t, "optional params must be trailing path segments in path '/invalid/:kind?/list'", err.Error())
+}
+
 func TestRouterStaticSectionBaseDirForFilePaths(t *testing.T) {
 	router, err := createRouter("routes-static.conf")
 	assert.FailNowOnError(t, err, "")
@@ -564,6 +1537,44 @@ func TestRoutePathConstraints(t *testing.T) {
 				},
 			},
 		},
+		{
+			label:      "multiple path parameters in segment with constraints",
+			name:       "files",
+			path:       "/files/:name[alpha].:ext[oneof=jpg png]/v:major[int].:minor",
+			actualpath: "/files/:name.:ext/v:major.:minor",
+			constraints: map[string]string{
+				"name":  "alpha",
+				"ext":   "oneof=jpg png",
+				"major": "int",
+			},
+		},
+		{
+			label:      "optional path parameter with constraints",
+			name:       "reports",
+			path:       "/reports/:year?[int]/:period[alpha]?",
+			actualpath: "/reports/:year?/:period?",
+			constraints: map[string]string{
+				"year":   "int",
+				"period": "alpha",
+			},
+		},
+		{
+			label:      "path parameter with regex constraint",
+			name:       "codes",
+			path:       "/codes/:code[regex([a-z]{2}-[0-9]+)]",
+			actualpath: "/codes/:code",
+			constraints: map[string]string{
+				"code": "regex([a-z]{2}-[0-9]+)",
+			},
+		},
+		{
+			label:       "path parameter with invalid constraint",
+			name:        "files",
+			path:        "/files/:name.[alpha]",
+			actualpath:  "/files/:name.[alpha]",
+			constraints: map[string]string{},
+			err:         errors.New("'files.path' has invalid contraint in path => '/files/:name.[alpha]' (param => ':name.[alpha]')"),
+		},
 	}
 
 	// validate := validator.New()
@@ -659,3 +1670,71 @@ func testdataBaseDir() string {
 	}
 	return filepath.Join(wd, "testdata")
 }
+
+func BenchmarkDomainLookup(b *testing.B) {
+	domain, req := benchmarkDomainRequest(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _, _ = domain.Lookup(req)
+	}
+}
+
+func BenchmarkDomainLookupInto(b *testing.B) {
+	domain, req := benchmarkDomainRequest(b)
+	pb := AcquireParamsBuffer()
+	defer ReleaseParamsBuffer(pb)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = domain.LookupInto(req, pb)
+	}
+}
+
+func benchmarkDomainRequest(b *testing.B) (*Domain, *http.Request) {
+	router, err := createRouter("routes.conf")
+	if err != nil {
+		b.Fatal(err)
+	}
+	req := createHTTPRequest("localhost:8080", "/hotels/12345/booking")
+	req.Method = ahttp.MethodGet
+	return router.Lookup(req.Host), req
+}
+
+func BenchmarkRouterLookupIndex(b *testing.B) {
+	router, hosts := benchmarkRouterDomains(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = router.Lookup(hosts[i%len(hosts)])
+	}
+}
+
+func BenchmarkRouterLookupScan(b *testing.B) {
+	router, hosts := benchmarkRouterDomains(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// lookup before host index, key is lowercased once and compared
+		_ = router.scanDomains(strings.ToLower(hosts[i%len(hosts)]))
+	}
+}
+
+// benchmarkRouterDomains method creates the router with white-label domains
+// and returns the hosts of last domains, it is the worst case of linear scan.
+func benchmarkRouterDomains(b *testing.B) (*Router, []string) {
+	router := &Router{}
+	for i := 0; i < 64; i++ {
+		d := router.AddDomain(fmt.Sprintf("tenant%d.com", i), "8080")
+		if err := router.AddDomainAlias(d, fmt.Sprintf("www.tenant%d.com", i)); err != nil {
+			b.Fatal(err)
+		}
+	}
+	router.AddDomain("*.sample.com", "8080")
+
+	var hosts []string
+	for i := 56; i < 64; i++ {
+		hosts = append(hosts, fmt.Sprintf("tenant%d.com:8080", i))
+	}
+	return router, hosts
+}
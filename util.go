@@ -5,9 +5,17 @@
 package router
 
 import (
+	"bytes"
+	"encoding"
+	"errors"
 	"fmt"
+	"net"
+	"net/url"
 	"path"
 	"strings"
+	"time"
+
+	"aahframework.org/essentials.v0"
 )
 
 const (
@@ -37,6 +45,55 @@ func addRegisteredAction(methods map[string]map[string]uint8, route *Route) {
 	}
 }
 
+// mergeRouteMeta method returns the merged meta values of parent and child,
+// child value takes precedence for same key. Merged map is a new map, so
+// parent meta is unchanged.
+func mergeRouteMeta(parent, child map[string]interface{}) map[string]interface{} {
+	if len(child) == 0 {
+		return parent
+	}
+	if len(parent) == 0 {
+		return child
+	}
+
+	meta := make(map[string]interface{}, len(parent)+len(child))
+	for k, v := range parent {
+		meta[k] = v
+	}
+	for k, v := range child {
+		meta[k] = v
+	}
+	return meta
+}
+
+// mergeValues method returns the parent values followed by child values,
+// duplicates are removed. It is used for route interceptors and tags.
+func mergeValues(parent, child []string) []string {
+	if len(child) == 0 {
+		return parent
+	}
+
+	values := make([]string, 0, len(parent)+len(child))
+	values = append(values, parent...)
+	for _, c := range child {
+		c = strings.TrimSpace(c)
+		if len(c) > 0 && !ess.IsSliceContainsString(values, c) {
+			values = append(values, c)
+		}
+	}
+	return values
+}
+
+// parseSunsetDate method parses the route sunset date, supported formats are
+// `2006-01-02` and RFC3339.
+func parseSunsetDate(v string) (time.Time, error) {
+	v = strings.TrimSpace(v)
+	if t, err := time.Parse("2006-01-02", v); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
+
 func routeConstraintExists(routePath string) bool {
 	sidx := strings.IndexByte(routePath, ruleStartByte)
 	eidx := strings.IndexByte(routePath, ruleEndByte)
@@ -55,14 +112,10 @@ func parseRouteConstraints(routeName, routePath string) (string, map[string]stri
 	constraints := make(map[string]string)
 	actualRoutePath := "/"
 	for _, seg := range strings.Split(routePath, "/")[1:] {
-		if seg[0] == paramByte || seg[0] == wildByte {
-			param, constraint, exists, valid := parameterConstraint(seg)
-			if exists {
-				if valid {
-					constraints[param[1:]] = constraint
-				} else {
-					return routePath, constraints, fmt.Errorf("'%s.path' has invalid contraint in path => '%s' (param => '%s')", routeName, routePath, seg)
-				}
+		if strings.IndexByte(seg, paramByte) >= 0 || strings.IndexByte(seg, wildByte) >= 0 {
+			param, valid := parseSegmentConstraints(seg, constraints)
+			if !valid {
+				return routePath, constraints, fmt.Errorf("'%s.path' has invalid contraint in path => '%s' (param => '%s')", routeName, routePath, seg)
 			}
 
 			actualRoutePath = path.Join(actualRoutePath, param)
@@ -74,25 +127,143 @@ func parseRouteConstraints(routeName, routePath string) (string, map[string]stri
 	return actualRoutePath, constraints, nil
 }
 
+// parseSegmentConstraints method parses the path segment constraints into
+// given map, path segment could have multiple params. For e.g.:
+// `:name[alpha].:ext[oneof=jpg png]`.
+//
 // Return values are -
-// 1. path param
-// 2. param constraint
-// 3. is constraint exists
-// 4. is constraint valid
-func parameterConstraint(pathSeg string) (string, string, bool, bool) {
-	sidx := strings.IndexByte(pathSeg, ruleStartByte)
-	eidx := strings.IndexByte(pathSeg, ruleEndByte)
+// 1. path segment without constraints
+// 2. is constraints valid
+func parseSegmentConstraints(pathSeg string, constraints map[string]string) (string, bool) {
+	seg := make([]byte, 0, len(pathSeg))
+	for i := 0; i < len(pathSeg); i++ {
+		switch pathSeg[i] {
+		case ruleStartByte:
+			end := constraintEnd(pathSeg, i)
+			if end == -1 {
+				return "", false
+			}
+
+			seg = []byte(strings.TrimRight(string(seg), " "))
+			param := paramNameSuffix(bytes.TrimSuffix(seg, []byte{optionalByte}))
+			constraint := strings.TrimSpace(pathSeg[i+1 : end])
+			if len(param) == 0 || len(constraint) == 0 {
+				return "", false
+			}
+
+			constraints[param] = constraint
+			i = end
+		case ruleEndByte:
+			return "", false
+		default:
+			seg = append(seg, pathSeg[i])
+		}
+	}
+	return string(seg), true
+}
 
-	// Validation rule exists but invalid
-	if (sidx == -1 && eidx > 0) || (sidx >= 0 && eidx == -1) {
-		return "", "", true, false
+// constraintEnd method returns the index of matching rule end byte for the
+// rule start byte at given index otherwise -1.
+func constraintEnd(pathSeg string, start int) int {
+	depth := 0
+	for i := start; i < len(pathSeg); i++ {
+		switch pathSeg[i] {
+		case ruleStartByte:
+			depth++
+		case ruleEndByte:
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// paramNameSuffix method returns the param name if the given path segment
+// ends with param otherwise empty string.
+func paramNameSuffix(seg []byte) string {
+	i := len(seg)
+	for i > 0 && isParamNameByte(seg[i-1]) {
+		i--
+	}
+	if i > 0 && i < len(seg) && seg[i-1] == paramByte {
+		return string(seg[i:])
 	}
 
-	constraint := strings.TrimSpace(pathSeg[sidx+1 : eidx])
-	return strings.TrimSpace(pathSeg[:sidx]),
-		constraint,
-		true,
-		len(constraint) > 0
+	// catch-all name
+	if idx := bytes.LastIndexByte(seg, wildByte); idx >= 0 && idx < len(seg)-1 {
+		return string(seg[idx+1:])
+	}
+	return ""
+}
+
+// unescapePathValue method returns the unescaped value of path param value,
+// if value is not escaped properly then it is returned as-is.
+func unescapePathValue(v string) string {
+	if strings.IndexByte(v, '%') == -1 {
+		return v
+	}
+	if uv, err := url.PathUnescape(v); err == nil {
+		return uv
+	}
+	return v
+}
+
+// formatPathValue method returns the string representation of the given value
+// for reverse URL. It is aware of `encoding.TextMarshaler` and `fmt.Stringer`,
+// so custom types for e.g.: ID types, formatted accordingly.
+func formatPathValue(v interface{}) (string, error) {
+	switch t := v.(type) {
+	case string:
+		return t, nil
+	case []byte:
+		return string(t), nil
+	case encoding.TextMarshaler:
+		b, err := t.MarshalText()
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	case fmt.Stringer:
+		return t.String(), nil
+	}
+	return fmt.Sprintf("%v", v), nil
+}
+
+// validatePathValue method validates the path param value does not change
+// the route path structure. Path separator is allowed only for catch-all or
+// if the value is going to be escaped on raw path, relative path segment `.`
+// or `..` is not allowed in any value.
+func validatePathValue(value string, catchAll, rawPath bool) error {
+	if !catchAll {
+		switch {
+		case len(value) == 0:
+			return errors.New("value is empty")
+		case !rawPath && strings.IndexByte(value, slashByte) >= 0:
+			return fmt.Errorf("value '%s' has path separator", value)
+		}
+	}
+
+	for _, seg := range strings.Split(strings.TrimPrefix(value, SlashString), SlashString) {
+		if seg == "." || seg == ".." {
+			return fmt.Errorf("value '%s' has relative path segment", value)
+		}
+	}
+	return nil
+}
+
+// escapePathValue method returns the escaped value of path param value,
+// catch-all value is escaped per path segment.
+func escapePathValue(v string, catchAll bool) string {
+	if !catchAll {
+		return url.PathEscape(v)
+	}
+
+	segments := strings.Split(v, SlashString)
+	for i := range segments {
+		segments[i] = url.PathEscape(segments[i])
+	}
+	return strings.Join(segments, SlashString)
 }
 
 func addSlashPrefix(v string) string {
@@ -101,3 +272,33 @@ func addSlashPrefix(v string) string {
 	}
 	return "/" + v
 }
+
+// splitHostPort method splits the given host into hostname and port, port is
+// empty if host does not have it. IPv6 address without port is returned as-is
+// with or without brackets.
+func splitHostPort(host string) (string, string) {
+	if hostname, port, err := net.SplitHostPort(host); err == nil {
+		return hostname, port
+	}
+	return host, ""
+}
+
+// isValidSubdomain method validates the given value is a valid subdomain,
+// it could have multiple labels for e.g.: `tenant1`, `eu.tenant1`.
+func isValidSubdomain(v string) bool {
+	if len(v) == 0 || len(v) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(v, ".") {
+		if len(label) == 0 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for i := 0; i < len(label); i++ {
+			c := label[i]
+			if !(c >= 'a' && c <= 'z') && !(c >= 'A' && c <= 'Z') && !(c >= '0' && c <= '9') && c != '-' {
+				return false
+			}
+		}
+	}
+	return true
+}